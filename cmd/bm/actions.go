@@ -0,0 +1,300 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+// tuiAction is an edit started from one of the TUIs.
+type tuiAction int
+
+const (
+	actionNone tuiAction = iota
+	actionRename
+	actionTags
+	actionDelete
+	actionAdd
+	// actionDescribe, actionPrune and actionExec are started from the
+	// command palette of `bm tui`.
+	actionDescribe
+	actionPrune
+	actionExec
+)
+
+// actionPrompt collects the value or confirmation for a pending action in a
+// line below the list. targets are the bookmark names the action applies to;
+// path is the directory being added for actionAdd.
+type actionPrompt struct {
+	action  tuiAction
+	targets []string
+	path    string
+	input   textinput.Model
+}
+
+func newActionPrompt(action tuiAction, targets []string, value string) actionPrompt {
+	input := textinput.New()
+	input.Prompt = ""
+	input.SetValue(value)
+	input.CursorEnd()
+	input.Focus()
+	return actionPrompt{action: action, targets: targets, input: input}
+}
+
+// newTagsPrompt edits the tags of targets. A single bookmark starts from its
+// current tags, which the value replaces. Several start empty and the value
+// lists edits applied to each: "tag" or "+tag" adds, "-tag" removes.
+func newTagsPrompt(targets, current []string) actionPrompt {
+	if len(targets) > 1 {
+		return newActionPrompt(actionTags, targets, "")
+	}
+	return newActionPrompt(actionTags, targets, strings.Join(current, ","))
+}
+
+func (p actionPrompt) active() bool { return p.action != actionNone }
+
+// confirm reports whether the action asks y/n instead of reading a value.
+func (p actionPrompt) confirm() bool {
+	return p.action == actionDelete || p.action == actionPrune
+}
+
+// update handles a key while the prompt is active. It reports whether the
+// action was submitted; escape (or anything but "y" when confirming a delete)
+// cancels it.
+func (p actionPrompt) update(msg tea.KeyMsg) (actionPrompt, bool, tea.Cmd) {
+	if p.confirm() {
+		if msg.String() == "y" {
+			return p, true, nil
+		}
+		return actionPrompt{}, false, nil
+	}
+	switch msg.Type {
+	case tea.KeyEnter:
+		return p, true, nil
+	case tea.KeyEsc, tea.KeyCtrlC:
+		return actionPrompt{}, false, nil
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return p, false, cmd
+}
+
+func (p actionPrompt) View() string {
+	var label string
+	switch p.action {
+	case actionRename:
+		label = "rename " + p.targets[0] + " to: "
+	case actionTags:
+		label = "tags for " + strings.Join(p.targets, ", ") + ": "
+		if len(p.targets) > 1 {
+			label = "tags for " + strings.Join(p.targets, ", ") + " (+add,-remove): "
+		}
+	case actionDelete:
+		return ui.prompt.Render("delete "+strings.Join(p.targets, ", ")+"? ") + "(y/n)"
+	case actionAdd:
+		label = "add " + p.path + " as: "
+	case actionDescribe:
+		label = "description for " + p.targets[0] + ": "
+	case actionPrune:
+		return ui.prompt.Render(fmt.Sprintf("delete %d broken bookmark(s) (%s)? ", len(p.targets), strings.Join(p.targets, ", "))) + "(y/n)"
+	case actionExec:
+		label = "run in " + p.targets[0] + ": "
+	}
+	return ui.prompt.Render(label) + p.input.View()
+}
+
+// tagEdits splits the edits of a bulk retag into tags to add and to remove.
+func tagEdits(tags []string) (add, remove []string) {
+	for _, tag := range tags {
+		if name, ok := strings.CutPrefix(tag, "-"); ok {
+			if name = strings.TrimSpace(name); name != "" {
+				remove = append(remove, name)
+			}
+			continue
+		}
+		if name := strings.TrimSpace(strings.TrimPrefix(tag, "+")); name != "" {
+			add = append(add, name)
+		}
+	}
+	return add, remove
+}
+
+// withoutTags returns tags minus those in remove.
+func withoutTags(tags, remove []string) []string {
+	var result []string
+	for _, tag := range tags {
+		if !bookmarks.ContainsTag(remove, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// applyAction saves a submitted action to the store and returns the updated
+// bookmarks, the name to highlight afterwards and a status line.
+func applyAction(storePath string, p actionPrompt) ([]bookmarks.Bookmark, string, string, error) {
+	entries, err := bookmarks.Load(storePath)
+	if err != nil {
+		return nil, "", "", err
+	}
+	value := strings.TrimSpace(p.input.Value())
+	now := time.Now().UTC()
+
+	var selected, status, message string
+	switch p.action {
+	case actionRename:
+		old := p.targets[0]
+		if err := validateName(value); err != nil {
+			return nil, "", "", err
+		}
+		if value == old {
+			return entries, old, "", nil
+		}
+		if findEntry(entries, value) >= 0 {
+			return nil, "", "", fmt.Errorf("bookmark already exists: %s", value)
+		}
+		i := findEntry(entries, old)
+		if i < 0 {
+			return nil, "", "", fmt.Errorf("bookmark not found: %s", old)
+		}
+		entries[i].Name = value
+		entries[i].UpdatedAt = now
+		selected, status, message = value, "renamed "+old+" to "+value, "rename "+old+" to "+value
+
+	case actionTags:
+		tags := bookmarks.NormalizeTags(value)
+		add, remove := tagEdits(tags)
+		multi := len(p.targets) > 1
+		if multi && len(add)+len(remove) == 0 {
+			return entries, p.targets[0], "", nil
+		}
+		for _, name := range p.targets {
+			i := findEntry(entries, name)
+			if i < 0 {
+				return nil, "", "", fmt.Errorf("bookmark not found: %s", name)
+			}
+			if multi {
+				entries[i].Tags = withoutTags(bookmarks.AddTags(entries[i].Tags, add), remove)
+			} else {
+				entries[i].Tags = tags
+			}
+			entries[i].UpdatedAt = now
+		}
+		selected, status, message = p.targets[0], "tagged "+strings.Join(p.targets, ", "), "update "+p.targets[0]
+		if len(p.targets) > 1 {
+			message = fmt.Sprintf("retag %d bookmark(s)", len(p.targets))
+		}
+
+	case actionDescribe:
+		i := findEntry(entries, p.targets[0])
+		if i < 0 {
+			return nil, "", "", fmt.Errorf("bookmark not found: %s", p.targets[0])
+		}
+		entries[i].Description = value
+		entries[i].UpdatedAt = now
+		selected, status, message = p.targets[0], "described "+p.targets[0], "update "+p.targets[0]
+
+	case actionDelete, actionPrune:
+		removed := map[string]bool{}
+		for _, name := range p.targets {
+			removed[name] = true
+		}
+		kept := make([]bookmarks.Bookmark, 0, len(entries))
+		for _, e := range entries {
+			if !removed[e.Name] {
+				kept = append(kept, e)
+			}
+		}
+		entries = kept
+		status, message = "deleted "+strings.Join(p.targets, ", "), "rm "+p.targets[0]
+		if len(p.targets) > 1 {
+			message = fmt.Sprintf("rm %d bookmark(s)", len(p.targets))
+		}
+		if p.action == actionPrune {
+			status, message = fmt.Sprintf("pruned %d broken bookmark(s)", len(p.targets)), fmt.Sprintf("prune %d bookmark(s)", len(p.targets))
+		}
+
+	case actionAdd:
+		if err := validateName(value); err != nil {
+			return nil, "", "", err
+		}
+		if findEntry(entries, value) >= 0 {
+			return nil, "", "", fmt.Errorf("bookmark already exists: %s", value)
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			return nil, "", "", err
+		}
+		entries = append(entries, bookmarks.Bookmark{
+			Name:      value,
+			Path:      p.path,
+			Tags:      bookmarks.ApplyRules(cfg.Rules, p.path),
+			CreatedAt: now,
+		})
+		selected, status, message = value, "added "+value, "add "+value
+
+	default:
+		return nil, "", "", errors.New("no action")
+	}
+
+	if err := saveStore(storePath, entries, message); err != nil {
+		return nil, "", "", err
+	}
+	return entries, selected, status, nil
+}
+
+// refreshEntries returns the bookmarks a view should show after an action:
+// the ones it showed before plus the selected one, taken from the saved store
+// in store order. Keeping previously shown entries means a retagged bookmark
+// does not vanish from a tag-filtered view.
+func refreshEntries(saved, shown []bookmarks.Bookmark, selected string) []bookmarks.Bookmark {
+	keep := map[string]bool{selected: true}
+	for _, e := range shown {
+		keep[e.Name] = true
+	}
+	var entries []bookmarks.Bookmark
+	for _, e := range saved {
+		if keep[e.Name] {
+			entries = append(entries, e)
+		}
+	}
+	return resolveRepoPaths(entries)
+}
+
+// addPrompt starts adding the current directory, proposing its base name.
+func addPrompt() (actionPrompt, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return actionPrompt{}, err
+	}
+	p := newActionPrompt(actionAdd, nil, filepath.Base(cwd))
+	p.path = cwd
+	return p, nil
+}
+
+func validateName(name string) error {
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		return errors.New("name cannot be empty")
+	}
+	if strings.ContainsAny(name, "\t\n") {
+		return errors.New("name cannot contain tabs or newlines")
+	}
+	return nil
+}
+
+func findEntry(entries []bookmarks.Bookmark, name string) int {
+	for i, e := range entries {
+		if e.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/charmbracelet/x/term"
+)
+
+// clipboardWriter copies text with the strategy set by the `clipboard`
+// config key:
+//
+//   - "auto" uses the system clipboard, falling back to OSC 52 when it fails,
+//     and goes straight to OSC 52 in SSH sessions, where the system clipboard
+//     would be the remote host's;
+//   - "system" only uses the system clipboard (xclip, xsel, pbcopy, ...);
+//   - "osc52" only asks the terminal to set its clipboard with an OSC 52
+//     escape sequence, which works over SSH and in containers.
+type clipboardWriter struct {
+	strategy string
+	system   func(string) error
+	// terminal opens the terminal OSC 52 sequences are written to.
+	terminal func() (io.WriteCloser, error)
+	getenv   func(string) string
+}
+
+func newClipboardWriter(strategy string) clipboardWriter {
+	if strategy == "" {
+		strategy = "auto"
+	}
+	return clipboardWriter{strategy: strategy, system: clipboard.WriteAll, terminal: openTerminal, getenv: os.Getenv}
+}
+
+// copy puts text on the clipboard and returns how: "clipboard" or "OSC 52".
+func (c clipboardWriter) copy(text string) (string, error) {
+	switch c.strategy {
+	case "system":
+		if err := c.system(text); err != nil {
+			return "", err
+		}
+		return "clipboard", nil
+	case "osc52":
+		return "OSC 52", c.osc52(text)
+	}
+	if c.getenv("SSH_TTY") == "" && c.getenv("SSH_CONNECTION") == "" {
+		err := c.system(text)
+		if err == nil {
+			return "clipboard", nil
+		}
+		if oscErr := c.osc52(text); oscErr != nil {
+			return "", fmt.Errorf("%w; OSC 52: %w", err, oscErr)
+		}
+		return "OSC 52", nil
+	}
+	return "OSC 52", c.osc52(text)
+}
+
+// osc52 writes the sequence setting the clipboard to text, wrapped for tmux
+// or screen so they pass it on to the outer terminal.
+func (c clipboardWriter) osc52(text string) error {
+	seq := osc52.New(text)
+	switch {
+	case c.getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(c.getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+	w, err := c.terminal()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	_, err = seq.WriteTo(w)
+	return err
+}
+
+// openTerminal returns the controlling terminal, or stderr when that is a
+// terminal and /dev/tty cannot be opened.
+func openTerminal() (io.WriteCloser, error) {
+	if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		return tty, nil
+	}
+	if term.IsTerminal(os.Stderr.Fd()) {
+		return nopCloser{os.Stderr}, nil
+	}
+	return nil, errors.New("no terminal to send OSC 52 to")
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// copyStatus describes a copy for the TUI status lines.
+func copyStatus(path, method string) string {
+	if method == "OSC 52" {
+		return "copied via OSC 52: " + path
+	}
+	return "copied: " + path
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+type fakeTerminal struct{ bytes.Buffer }
+
+func (*fakeTerminal) Close() error { return nil }
+
+// fakeClipboard returns a writer whose system clipboard fails with
+// systemErr (when set) and whose terminal output lands in the returned
+// buffer.
+func fakeClipboard(strategy string, systemErr error, env map[string]string) (clipboardWriter, *string, *fakeTerminal) {
+	var system string
+	tty := &fakeTerminal{}
+	return clipboardWriter{
+		strategy: strategy,
+		system: func(text string) error {
+			if systemErr != nil {
+				return systemErr
+			}
+			system = text
+			return nil
+		},
+		terminal: func() (io.WriteCloser, error) { return tty, nil },
+		getenv:   func(k string) string { return env[k] },
+	}, &system, tty
+}
+
+func TestClipboardWriter(t *testing.T) {
+	osc := "\x1b]52;c;L3NyYy9hcGk=\x07" // "/src/api"
+	noClip := errors.New("no clipboard utilities available")
+	tests := []struct {
+		name       string
+		strategy   string
+		systemErr  error
+		env        map[string]string
+		wantMethod string
+		wantSystem string
+		wantTTY    string
+		wantErr    string
+	}{
+		{name: "auto uses the system clipboard", strategy: "auto", wantMethod: "clipboard", wantSystem: "/src/api"},
+		{name: "auto falls back to OSC 52", strategy: "auto", systemErr: noClip, wantMethod: "OSC 52", wantTTY: osc},
+		{name: "auto prefers OSC 52 over SSH", strategy: "auto", env: map[string]string{"SSH_TTY": "/dev/pts/1"}, wantMethod: "OSC 52", wantTTY: osc},
+		{name: "system reports failures", strategy: "system", systemErr: noClip, wantErr: "no clipboard utilities"},
+		{name: "osc52 skips the system clipboard", strategy: "osc52", wantMethod: "OSC 52", wantTTY: osc},
+		{name: "tmux passthrough", strategy: "osc52", env: map[string]string{"TMUX": "/tmp/tmux"}, wantMethod: "OSC 52", wantTTY: "\x1bPtmux;\x1b\x1b]52;c;L3NyYy9hcGk=\x07\x1b\\"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, system, tty := fakeClipboard(tt.strategy, tt.systemErr, tt.env)
+			method, err := c.copy("/src/api")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("copy() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("copy() error = %v", err)
+			}
+			if method != tt.wantMethod || *system != tt.wantSystem || tty.String() != tt.wantTTY {
+				t.Fatalf("copy() = %q, system %q, terminal %q", method, *system, tty.String())
+			}
+		})
+	}
+}
+
+func TestClipboardWriter_NoTerminal(t *testing.T) {
+	c, _, _ := fakeClipboard("auto", errors.New("no xclip"), nil)
+	c.terminal = func() (io.WriteCloser, error) { return nil, errors.New("no terminal to send OSC 52 to") }
+	if _, err := c.copy("/src/api"); err == nil || !strings.Contains(err.Error(), "no xclip; OSC 52: no terminal") {
+		t.Fatalf("copy() error = %v", err)
+	}
+}
+
+func TestTableModel_CopyStatus(t *testing.T) {
+	entries := []bookmarks.Bookmark{{Name: "api", Path: "/src/api"}}
+	m := newTableModel("", entries, "bm table", mustColumns(t, "name", "path"))
+	m.clipboard, _, _ = fakeClipboard("system", errors.New("no clipboard utilities available"), nil)
+	next, _ := m.Update(keyMsg("c"))
+	if view := ansi.Strip(next.View()); !strings.Contains(view, "copy failed: no clipboard utilities available") {
+		t.Fatalf("copy error not shown:\n%s", view)
+	}
+
+	m.clipboard, _, _ = fakeClipboard("osc52", nil, nil)
+	next, _ = m.Update(keyMsg("c"))
+	if view := ansi.Strip(next.View()); !strings.Contains(view, "copied via OSC 52: /src/api") {
+		t.Fatalf("copy status not shown:\n%s", view)
+	}
+}
+
+func TestFindModel_CopyStatus(t *testing.T) {
+	entries := []bookmarks.Bookmark{{Name: "api", Path: "/src/api"}}
+	var m tea.Model = newFindModel("", entries, "bm find", nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 60, Height: 20})
+	fm := m.(findModel)
+	fm.clipboard, _, _ = fakeClipboard("system", errors.New("no clipboard utilities available"), nil)
+	m, _ = fm.Update(keyMsg("c"))
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "copy failed: no clipboard utilities available") {
+		t.Fatalf("copy error not shown:\n%s", view)
+	}
+}
+
+func TestCmdCopy_Errors(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "bm.tsv")
+	if err := bookmarks.Save(storePath, promptEntries()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := cmdCopy(storePath, nil); err == nil || !strings.Contains(err.Error(), "usage: bm copy <name>") {
+		t.Fatalf("cmdCopy() error = %v", err)
+	}
+	if err := cmdCopy(storePath, []string{"nope"}); err == nil || !strings.Contains(err.Error(), "bookmark not found: nope") {
+		t.Fatalf("cmdCopy(nope) error = %v", err)
+	}
+	writeConfig(t, "clipboard = xclip\n")
+	if err := cmdCopy(storePath, []string{"api"}); err == nil || !strings.Contains(err.Error(), "expected auto, system or osc52") {
+		t.Fatalf("cmdCopy() with a bad strategy error = %v", err)
+	}
+}
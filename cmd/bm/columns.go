@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+// defaultTableColumns are shown by `bm table` unless --columns or the
+// table.columns config key says otherwise.
+var defaultTableColumns = []string{"name", "path", "tags", "created", "desc"}
+
+// cellContext carries the data cells need beyond the bookmark itself.
+type cellContext struct {
+	visits bookmarks.Visits
+	git    map[string]string
+	now    time.Time
+}
+
+// tableColumn describes a column `bm table` can show. width is used until
+// the terminal size is known; min is the narrowest the column gets and flex
+// its share of any spare width (0 keeps it fixed).
+type tableColumn struct {
+	key   string
+	title string
+	width int
+	min   int
+	flex  int
+	cell  func(e bookmarks.Bookmark, c cellContext) string
+	// compare orders rows when sorting by the column; nil compares the cells
+	// ignoring case.
+	compare func(a, b bookmarks.Bookmark, c cellContext) int
+}
+
+var tableColumns = []tableColumn{
+	{key: "name", title: "Name", width: 18, min: 10, flex: 2,
+		cell: func(e bookmarks.Bookmark, _ cellContext) string { return e.Name }},
+	{key: "path", title: "Path", width: 48, min: 16, flex: 5,
+		cell: func(e bookmarks.Bookmark, _ cellContext) string { return e.Path }},
+	{key: "tags", title: "Tags", width: 20, min: 8, flex: 2,
+		cell: func(e bookmarks.Bookmark, _ cellContext) string { return strings.Join(e.Tags, ",") }},
+	{key: "created", title: "Created", width: 10, min: 10,
+		cell:    func(e bookmarks.Bookmark, _ cellContext) string { return formatDate(e.CreatedAt) },
+		compare: func(a, b bookmarks.Bookmark, _ cellContext) int { return a.CreatedAt.Compare(b.CreatedAt) }},
+	{key: "updated", title: "Updated", width: 10, min: 10,
+		cell:    func(e bookmarks.Bookmark, _ cellContext) string { return formatDate(e.UpdatedAt) },
+		compare: func(a, b bookmarks.Bookmark, _ cellContext) int { return a.UpdatedAt.Compare(b.UpdatedAt) }},
+	{key: "desc", title: "Description", width: 30, min: 12, flex: 3,
+		cell: func(e bookmarks.Bookmark, _ cellContext) string { return e.Description }},
+	{key: "visits", title: "Visits", width: 6, min: 6,
+		cell: func(e bookmarks.Bookmark, c cellContext) string {
+			if n := c.visits[e.Path].Count; n > 0 {
+				return strconv.Itoa(n)
+			}
+			return ""
+		},
+		compare: func(a, b bookmarks.Bookmark, c cellContext) int {
+			return c.visits[a.Path].Count - c.visits[b.Path].Count
+		}},
+	{key: "last", title: "Last visit", width: 10, min: 10,
+		cell: func(e bookmarks.Bookmark, c cellContext) string {
+			if last := c.visits[e.Path].Last; !last.IsZero() {
+				return formatAge(last, c.now)
+			}
+			return ""
+		},
+		compare: func(a, b bookmarks.Bookmark, c cellContext) int {
+			return c.visits[a.Path].Last.Compare(c.visits[b.Path].Last)
+		}},
+	{key: "git", title: "Git", width: 18, min: 10, flex: 2,
+		cell: func(e bookmarks.Bookmark, c cellContext) string {
+			// "…" until loadGitStatus reports.
+			if cell, ok := c.git[e.Path]; ok {
+				return cell
+			}
+			return "…"
+		}},
+}
+
+// lookupColumns returns the columns named by keys, in order.
+func lookupColumns(keys []string) ([]tableColumn, error) {
+	var columns []tableColumn
+	for _, key := range keys {
+		found := false
+		for _, c := range tableColumns {
+			if c.key == key {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			names := make([]string, 0, len(tableColumns))
+			for _, c := range tableColumns {
+				names = append(names, c.key)
+			}
+			return nil, fmt.Errorf("unknown column: %s (expected %s)", key, strings.Join(names, ", "))
+		}
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns")
+	}
+	return columns, nil
+}
+
+// layoutColumns sizes columns to fit a table width cells wide, each cell
+// padded by one space on both sides. Columns start at their minimum and
+// share what is left by flex; a width of 0 keeps the default widths.
+func layoutColumns(columns []tableColumn, width int) []int {
+	widths := make([]int, len(columns))
+	if width <= 0 {
+		for i, c := range columns {
+			widths[i] = c.width
+		}
+		return widths
+	}
+	spare, flex := width-2*len(columns), 0
+	for i, c := range columns {
+		widths[i] = c.min
+		spare -= c.min
+		flex += c.flex
+	}
+	if spare <= 0 || flex == 0 {
+		return widths
+	}
+	given := 0
+	for i, c := range columns {
+		extra := spare * c.flex / flex
+		widths[i] += extra
+		given += extra
+	}
+	// Rounding leftovers go to the first flexible column.
+	for i, c := range columns {
+		if c.flex > 0 {
+			widths[i] += spare - given
+			break
+		}
+	}
+	return widths
+}
+
+// tableHeader returns the table columns with sizes and the sort indicator
+// on the sorted column (sortCol < 0: store order).
+func tableHeader(columns []tableColumn, widths []int, sortCol int, desc bool) []table.Column {
+	out := make([]table.Column, len(columns))
+	for i, c := range columns {
+		title := c.title
+		if i == sortCol {
+			if desc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		out[i] = table.Column{Title: title, Width: widths[i]}
+	}
+	return out
+}
+
+// middleEllipsis shortens s to width cells by replacing its middle with "…",
+// keeping both ends of a path readable; the end gets the odd cell.
+func middleEllipsis(s string, width int) string {
+	if ansi.StringWidth(s) <= width {
+		return s
+	}
+	if width <= 1 {
+		return ansi.Truncate(s, width, "")
+	}
+	head := (width - 1) / 2
+	tail := width - 1 - head
+	return ansi.Truncate(s, head, "") + "…" + ansi.TruncateLeft(s, ansi.StringWidth(s)-tail, "")
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(time.Local).Format("2006-01-02")
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestMiddleEllipsis(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{s: "/src/api", width: 20, want: "/src/api"},
+		{s: "/home/me/src/project/api", width: 11, want: "/home…t/api"},
+		{s: "/home/me/src/project/api", width: 10, want: "/hom…t/api"},
+		{s: "/home", width: 1, want: "/"},
+	}
+	for _, tt := range tests {
+		got := middleEllipsis(tt.s, tt.width)
+		if got != tt.want {
+			t.Fatalf("middleEllipsis(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+		if w := ansi.StringWidth(got); w > tt.width {
+			t.Fatalf("middleEllipsis(%q, %d) is %d wide", tt.s, tt.width, w)
+		}
+	}
+}
+
+func TestLayoutColumns(t *testing.T) {
+	columns := mustColumns(t, "name", "path", "created")
+	if got := layoutColumns(columns, 0); !reflect.DeepEqual(got, []int{18, 48, 10}) {
+		t.Fatalf("default widths = %v", got)
+	}
+	for _, width := range []int{60, 100, 173} {
+		got := layoutColumns(columns, width)
+		if got[2] != 10 {
+			t.Fatalf("width %d: fixed column resized: %v", width, got)
+		}
+		if total := got[0] + got[1] + got[2] + 2*len(columns); total != width {
+			t.Fatalf("width %d: columns fill %d: %v", width, total, got)
+		}
+		if got[1] <= got[0] {
+			t.Fatalf("width %d: path not wider than name: %v", width, got)
+		}
+	}
+	// Too narrow: columns keep their minimum.
+	if got := layoutColumns(columns, 20); !reflect.DeepEqual(got, []int{10, 16, 10}) {
+		t.Fatalf("narrow widths = %v", got)
+	}
+}
+
+func TestLookupColumns(t *testing.T) {
+	if _, err := lookupColumns([]string{"name", "size"}); err == nil || !strings.Contains(err.Error(), "unknown column: size") {
+		t.Fatalf("lookupColumns() error = %v", err)
+	}
+	columns := mustColumns(t, "visits", "last", "git")
+	if columns[0].title != "Visits" || columns[2].key != "git" {
+		t.Fatalf("columns = %#v", columns)
+	}
+}
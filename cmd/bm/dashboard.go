@@ -0,0 +1,856 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+// ----------------
+// DASHBOARD (bm tui)
+// ----------------
+
+// dashTab indexes the tabs of the dashboard.
+type dashTab int
+
+const (
+	tabBookmarks dashTab = iota
+	tabTags
+	tabRecent
+	tabBroken
+)
+
+var dashTabNames = []string{"Bookmarks", "Tags", "Recent", "Broken"}
+
+// tagItem is a tag in the Tags tab.
+type tagItem struct {
+	tag   string
+	count int
+}
+
+func (i tagItem) Title() string       { return i.tag }
+func (i tagItem) Description() string { return fmt.Sprintf("%d bookmark(s)", i.count) }
+func (i tagItem) FilterValue() string { return i.tag }
+
+// recentItem is a visited bookmark in the Recent tab.
+type recentItem struct {
+	b     bookmarks.Bookmark
+	visit bookmarks.Visit
+	now   time.Time
+}
+
+func (i recentItem) Title() string { return i.b.Name }
+
+func (i recentItem) Description() string {
+	age := formatAge(i.visit.Last, i.now)
+	if age != "now" {
+		age += " ago"
+	}
+	return fmt.Sprintf("%s · %d visit(s) · %s", age, i.visit.Count, i.b.Path)
+}
+
+func (i recentItem) FilterValue() string { return i.b.Name + " " + i.b.Path }
+
+// brokenItem is a bookmark whose directory is missing, in the Broken tab.
+type brokenItem struct {
+	b bookmarks.Bookmark
+}
+
+func (i brokenItem) Title() string       { return i.b.Name }
+func (i brokenItem) Description() string { return "missing: " + i.b.Path }
+func (i brokenItem) FilterValue() string { return i.b.Name + " " + i.b.Path }
+
+// itemBookmark returns the bookmark behind a list item of any tab.
+func itemBookmark(item list.Item) (bookmarks.Bookmark, bool) {
+	switch it := item.(type) {
+	case bookmarkItem:
+		return it.b, true
+	case recentItem:
+		return it.b, true
+	case brokenItem:
+		return it.b, true
+	}
+	return bookmarks.Bookmark{}, false
+}
+
+// missingPath reports whether a bookmark's directory no longer exists.
+func missingPath(path string) bool {
+	_, err := os.Stat(path)
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// brokenMsg delivers which bookmark directories are missing.
+type brokenMsg struct {
+	missing map[string]bool
+}
+
+// checkBroken looks for the entries' directories off the UI goroutine, since
+// a stat can hang on a network mount.
+func checkBroken(entries []bookmarks.Bookmark) tea.Cmd {
+	paths := make([]string, 0, len(entries))
+	for _, e := range entries {
+		paths = append(paths, e.Path)
+	}
+	return func() tea.Msg {
+		missing := map[string]bool{}
+		for _, path := range paths {
+			if missingPath(path) {
+				missing[path] = true
+			}
+		}
+		return brokenMsg{missing: missing}
+	}
+}
+
+// paletteCommand is an entry of the command palette.
+type paletteCommand struct {
+	name string
+	help string
+}
+
+var paletteCommands = []paletteCommand{
+	{name: "jump", help: "jump to the bookmark"},
+	{name: "add", help: "bookmark the current directory"},
+	{name: "edit name", help: "rename the bookmark"},
+	{name: "edit tags", help: "change the tags of the marked bookmarks"},
+	{name: "edit description", help: "change the bookmark's description"},
+	{name: "delete", help: "delete the marked bookmarks"},
+	{name: "prune", help: "delete every bookmark whose directory is missing"},
+	{name: "exec", help: "run a shell command in the bookmark's directory"},
+	{name: "open", help: "open the directory in the file manager"},
+	{name: "copy", help: "copy the path to the clipboard"},
+	{name: "quit", help: "quit without choosing"},
+}
+
+// commandPalette lets the user pick a paletteCommand by typing part of its
+// name.
+type commandPalette struct {
+	open   bool
+	input  textinput.Model
+	cursor int
+}
+
+func newCommandPalette() commandPalette {
+	input := textinput.New()
+	input.Prompt = ": "
+	input.Focus()
+	return commandPalette{open: true, input: input}
+}
+
+// matches returns the commands whose name contains the typed text.
+func (p commandPalette) matches() []paletteCommand {
+	query := strings.ToLower(strings.TrimSpace(p.input.Value()))
+	var out []paletteCommand
+	for _, c := range paletteCommands {
+		if strings.Contains(c.name, query) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// update handles a key while the palette is open and returns the command to
+// run once one is chosen; esc closes the palette.
+func (p commandPalette) update(msg tea.KeyMsg) (commandPalette, string, tea.Cmd) {
+	matches := p.matches()
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		return commandPalette{}, "", nil
+	case "up", "ctrl+p":
+		p.cursor = max(0, p.cursor-1)
+		return p, "", nil
+	case "down", "ctrl+n":
+		p.cursor = min(max(0, len(matches)-1), p.cursor+1)
+		return p, "", nil
+	case "enter":
+		if p.cursor < len(matches) {
+			return commandPalette{}, matches[p.cursor].name, nil
+		}
+		return p, "", nil
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	p.cursor = 0
+	return p, "", cmd
+}
+
+// View draws the palette in a box centered in width by height.
+func (p commandPalette) View(width, height int) string {
+	lines := []string{p.input.View(), ""}
+	matches := p.matches()
+	nameWidth := 0
+	for _, c := range paletteCommands {
+		nameWidth = max(nameWidth, len(c.name))
+	}
+	for i, c := range matches {
+		name := c.name + strings.Repeat(" ", nameWidth-len(c.name))
+		if i == p.cursor {
+			lines = append(lines, ui.cursor.Render("› "+name)+"  "+c.help)
+		} else {
+			lines = append(lines, "  "+name+"  "+ui.dim.Render(c.help))
+		}
+	}
+	if len(matches) == 0 {
+		lines = append(lines, ui.dim.Render("no matching command"))
+	}
+	box := ui.helpBorder.Render(ui.heading.Render("Commands") + "\n\n" + strings.Join(lines, "\n"))
+	if width <= 0 {
+		return box
+	}
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, lipgloss.NewStyle().MaxWidth(width).Render(box))
+}
+
+// execDoneMsg reports the end of a command started with the exec command.
+type execDoneMsg struct {
+	name, command string
+	err           error
+}
+
+// dashboardModel is `bm tui`: the find view on its Bookmarks tab plus tabs
+// listing tags, recently visited and broken bookmarks, each with a details
+// pane, and a command palette.
+type dashboardModel struct {
+	storePath string
+	entries   []bookmarks.Bookmark
+	visits    bookmarks.Visits
+	now       time.Time
+	tab       dashTab
+	find      findModel
+	tags      list.Model
+	recent    list.Model
+	broken    list.Model
+	// missing holds the missing directories found by the last checkBroken.
+	missing map[string]bool
+	// previews is shared with find, which fills it for the Bookmarks tab.
+	previews      map[string]*previewData
+	palette       commandPalette
+	prompt        actionPrompt
+	status        string
+	keys          keyMap
+	clipboard     clipboardWriter
+	showHelp      bool
+	width, height int
+	selected      []string
+}
+
+func newDashboardModel(storePath string, entries []bookmarks.Bookmark, visits bookmarks.Visits, now time.Time) dashboardModel {
+	find := newFindModel(storePath, entries, "Bookmarks", nil)
+	find.embedded = true
+	d := dashboardModel{
+		storePath: storePath,
+		visits:    visits,
+		now:       now,
+		find:      find,
+		tags:      newDashList("Tags"),
+		recent:    newDashList("Recently visited"),
+		broken:    newDashList("Missing directories"),
+		previews:  find.previews,
+		clipboard: newClipboardWriter("auto"),
+	}
+	d.setKeyMap(defaultKeyMap())
+	d.setEntries(entries, "")
+	return d
+}
+
+func newDashList(title string) list.Model {
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles = ui.item
+	lm := list.New(nil, delegate, 0, 0)
+	lm.Title = title
+	lm.Styles = ui.list
+	lm.Help.Styles = ui.help
+	lm.SetShowStatusBar(true)
+	lm.SetFilteringEnabled(true)
+	// The dashboard handles quitting and help itself.
+	lm.KeyMap.Quit.SetEnabled(false)
+	lm.KeyMap.ShowFullHelp.SetEnabled(false)
+	lm.KeyMap.CloseFullHelp.SetEnabled(false)
+	return lm
+}
+
+// setKeyMap installs keys in the dashboard and its find view.
+func (d *dashboardModel) setKeyMap(keys keyMap) {
+	d.keys = keys
+	d.find.setKeyMap(keys)
+	for _, l := range []*list.Model{&d.tags, &d.recent, &d.broken} {
+		lk := list.DefaultKeyMap()
+		l.KeyMap.PrevPage, l.KeyMap.NextPage = lk.PrevPage, lk.NextPage
+		keys.unbind(&l.KeyMap.PrevPage)
+		keys.unbind(&l.KeyMap.NextPage)
+	}
+}
+
+// setEntries shows a new set of bookmarks in every tab, highlighting the
+// named one in the Bookmarks tab.
+func (d *dashboardModel) setEntries(entries []bookmarks.Bookmark, highlight string) tea.Cmd {
+	if highlight == "" {
+		highlight = d.find.highlighted()
+	}
+	d.find.pool = entries
+	d.find.sidebar.setEntries(entries)
+	return tea.Batch(d.find.applyTagFilter(highlight), d.refreshTabs())
+}
+
+// refreshTabs rebuilds the Tags, Recent and Broken tabs from the find view's
+// bookmarks, which it edits itself, and checks for missing directories again.
+func (d *dashboardModel) refreshTabs() tea.Cmd {
+	d.entries = d.find.pool
+
+	tags, counts := tagCounts(d.entries)
+	tagItems := make([]list.Item, 0, len(tags))
+	for _, tag := range tags {
+		tagItems = append(tagItems, tagItem{tag: tag, count: counts[tag]})
+	}
+
+	var recent []recentItem
+	for _, e := range d.entries {
+		if v := d.visits[e.Path]; v.Count > 0 {
+			recent = append(recent, recentItem{b: e, visit: v, now: d.now})
+		}
+	}
+	sort.SliceStable(recent, func(i, j int) bool { return recent[i].visit.Last.After(recent[j].visit.Last) })
+	recentItems := make([]list.Item, 0, len(recent))
+	for _, it := range recent {
+		recentItems = append(recentItems, it)
+	}
+
+	return tea.Batch(d.tags.SetItems(tagItems), d.recent.SetItems(recentItems), d.setBroken(), checkBroken(d.entries))
+}
+
+// setBroken lists the bookmarks whose directories were found missing.
+func (d *dashboardModel) setBroken() tea.Cmd {
+	var items []list.Item
+	for _, e := range d.entries {
+		if d.missing[e.Path] {
+			items = append(items, brokenItem{b: e})
+		}
+	}
+	return d.broken.SetItems(items)
+}
+
+// tabList returns the list shown by a tab other than Bookmarks.
+func (d *dashboardModel) tabList() *list.Model {
+	switch d.tab {
+	case tabTags:
+		return &d.tags
+	case tabRecent:
+		return &d.recent
+	case tabBroken:
+		return &d.broken
+	}
+	return nil
+}
+
+// current returns the highlighted bookmark of the current tab.
+func (d dashboardModel) current() (bookmarks.Bookmark, bool) {
+	if d.tab == tabBookmarks {
+		return itemBookmark(d.find.list.SelectedItem())
+	}
+	return itemBookmark(d.tabList().SelectedItem())
+}
+
+// targets returns the bookmarks an edit applies to: the marked ones on the
+// Bookmarks tab, otherwise the highlighted one.
+func (d dashboardModel) targets() []string {
+	if d.tab == tabBookmarks {
+		return d.find.targets()
+	}
+	if e, ok := d.current(); ok {
+		return []string{e.Name}
+	}
+	return nil
+}
+
+func (d dashboardModel) Init() tea.Cmd {
+	return tea.Batch(d.find.Init(), checkBroken(d.entries))
+}
+
+// bodyHeight is the height left between the tab bar and the footer.
+func (d dashboardModel) bodyHeight() int {
+	return max(1, d.height-2)
+}
+
+// listWidth is the width of the list beside the details pane.
+func (d dashboardModel) listWidth() int {
+	return d.width * 2 / 5
+}
+
+func (d *dashboardModel) resize() {
+	m, _ := d.find.Update(tea.WindowSizeMsg{Width: d.width, Height: d.bodyHeight()})
+	d.find = m.(findModel)
+	for _, l := range []*list.Model{&d.tags, &d.recent, &d.broken} {
+		setListSize(l, d.listWidth(), d.bodyHeight())
+	}
+}
+
+// loadPreview starts loading the details of the highlighted bookmark.
+func (d dashboardModel) loadPreview() tea.Cmd {
+	if d.tab == tabBookmarks {
+		return d.find.loadPreview()
+	}
+	e, ok := d.current()
+	if !ok || d.width == 0 {
+		return nil
+	}
+	if _, seen := d.previews[e.Path]; seen {
+		return nil
+	}
+	d.previews[e.Path] = nil
+	return loadPreview(e.Path)
+}
+
+func (d dashboardModel) setTab(tab dashTab) (tea.Model, tea.Cmd) {
+	d.tab = tab
+	return d, d.loadPreview()
+}
+
+func (d dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case previewMsg:
+		data := msg.data
+		d.previews[msg.path] = &data
+		return d, nil
+	case brokenMsg:
+		d.missing = msg.missing
+		cmd := d.setBroken()
+		return d, tea.Batch(cmd, d.loadPreview())
+	case execDoneMsg:
+		var exitErr *exec.ExitError
+		switch {
+		case errors.As(msg.err, &exitErr):
+			d.status = ui.error.Render(fmt.Sprintf("%s: exit status %d", msg.command, exitErr.ExitCode()))
+		case msg.err != nil:
+			d.status = ui.error.Render(msg.err.Error())
+		default:
+			d.status = ui.status.Render("ran " + msg.command + " in " + msg.name)
+		}
+		return d, nil
+	case tea.WindowSizeMsg:
+		d.width, d.height = msg.Width, msg.Height
+		d.resize()
+		return d, d.loadPreview()
+	case tea.MouseMsg:
+		return d.updateMouse(msg)
+	case tea.KeyMsg:
+		return d.updateKey(msg)
+	}
+	if d.tab == tabBookmarks {
+		return d.forwardFind(msg)
+	}
+	var cmd tea.Cmd
+	*d.tabList(), cmd = d.tabList().Update(msg)
+	return d, cmd
+}
+
+func (d dashboardModel) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if d.palette.open {
+		palette, command, cmd := d.palette.update(msg)
+		d.palette = palette
+		if command != "" {
+			return d.runCommand(command)
+		}
+		return d, cmd
+	}
+	if d.prompt.active() {
+		return d.updatePrompt(msg)
+	}
+	if d.showHelp {
+		// Any key closes the help overlay.
+		d.showHelp = false
+		return d, nil
+	}
+	if d.tab == tabBookmarks {
+		f := d.find
+		if f.prompt.active() || f.list.FilterState() == list.Filtering || f.sidebar.focused || f.showHelp {
+			return d.forwardFind(msg)
+		}
+	} else if d.tabList().FilterState() == list.Filtering {
+		var cmd tea.Cmd
+		*d.tabList(), cmd = d.tabList().Update(msg)
+		return d, cmd
+	}
+
+	d.status = ""
+	switch msg.String() {
+	case "1", "2", "3", "4":
+		return d.setTab(dashTab(msg.String()[0] - '1'))
+	case "shift+tab":
+		n := dashTab(len(dashTabNames))
+		return d.setTab((d.tab + n - 1) % n)
+	case ":", "ctrl+p":
+		d.palette = newCommandPalette()
+		return d, textinput.Blink
+	}
+	if d.tab == tabBookmarks {
+		return d.forwardFind(msg)
+	}
+
+	switch d.keys.action(msg) {
+	case "quit":
+		return d, tea.Quit
+	case "help":
+		d.showHelp = true
+		return d, nil
+	case "jump":
+		switch d.tab {
+		case tabTags:
+			return d.showTag()
+		case tabBroken:
+			if e, ok := d.current(); ok {
+				d.status = ui.error.Render("directory is missing: " + e.Path)
+			}
+			return d, nil
+		}
+		return d.runCommand("jump")
+	case "copy":
+		return d.runCommand("copy")
+	case "rename":
+		return d.runCommand("edit name")
+	case "tags":
+		return d.runCommand("edit tags")
+	case "delete":
+		return d.runCommand("delete")
+	case "add":
+		return d.runCommand("add")
+	}
+	var cmd tea.Cmd
+	*d.tabList(), cmd = d.tabList().Update(msg)
+	return d, tea.Batch(cmd, d.loadPreview())
+}
+
+// forwardFind hands msg to the find view. Edits made there are picked up by
+// the other tabs, and a jump ends the dashboard too.
+func (d dashboardModel) forwardFind(msg tea.Msg) (tea.Model, tea.Cmd) {
+	editing := d.find.prompt.active()
+	m, cmd := d.find.Update(msg)
+	d.find = m.(findModel)
+	if editing && !d.find.prompt.active() {
+		cmd = tea.Batch(cmd, d.refreshTabs())
+	}
+	d.selected = d.find.selected
+	return d, cmd
+}
+
+// showTag switches to the Bookmarks tab filtered to the highlighted tag.
+func (d dashboardModel) showTag() (tea.Model, tea.Cmd) {
+	it, ok := d.tags.SelectedItem().(tagItem)
+	if !ok {
+		return d, nil
+	}
+	clear(d.find.sidebar.active)
+	d.find.sidebar.active[it.tag] = true
+	d.find.sidebar.open = true
+	d.find.sidebar.cursor = max(0, sort.SearchStrings(d.find.sidebar.tags, it.tag))
+	d.tab = tabBookmarks
+	cmd := d.find.applyTagFilter(d.find.highlighted())
+	return d, tea.Batch(cmd, d.loadPreview())
+}
+
+// runCommand runs a command palette entry (or the key bound to it) on the
+// highlighted bookmark.
+func (d dashboardModel) runCommand(name string) (tea.Model, tea.Cmd) {
+	e, ok := d.current()
+	switch name {
+	case "add", "prune", "quit":
+	default:
+		if !ok {
+			d.status = ui.error.Render("no bookmark highlighted")
+			return d, nil
+		}
+	}
+
+	switch name {
+	case "quit":
+		return d, tea.Quit
+	case "jump":
+		d.selected = []string{e.Name}
+		return d, tea.Quit
+	case "copy":
+		method, err := d.clipboard.copy(e.Path)
+		if err != nil {
+			d.status = ui.error.Render("copy failed: " + err.Error())
+		} else {
+			d.status = ui.status.Render(copyStatus(e.Path, method))
+		}
+	case "open":
+		if err := openDir(e.Path); err != nil {
+			d.status = ui.error.Render(err.Error())
+		} else {
+			d.status = ui.status.Render("opened " + e.Path)
+		}
+	case "add":
+		prompt, err := addPrompt()
+		if err != nil {
+			d.status = ui.error.Render(err.Error())
+			return d, nil
+		}
+		d.prompt = prompt
+	case "edit name":
+		d.prompt = newActionPrompt(actionRename, []string{e.Name}, e.Name)
+	case "edit tags":
+		d.prompt = newTagsPrompt(d.targets(), e.Tags)
+	case "edit description":
+		d.prompt = newActionPrompt(actionDescribe, []string{e.Name}, e.Description)
+	case "delete":
+		d.prompt = newActionPrompt(actionDelete, d.targets(), "")
+	case "prune":
+		var names []string
+		for _, item := range d.broken.Items() {
+			names = append(names, item.(brokenItem).b.Name)
+		}
+		if len(names) == 0 {
+			d.status = ui.status.Render("no broken bookmarks")
+			return d, nil
+		}
+		d.prompt = newActionPrompt(actionPrune, names, "")
+	case "exec":
+		d.prompt = newActionPrompt(actionExec, []string{e.Name}, "")
+		d.prompt.path = e.Path
+	}
+	return d, nil
+}
+
+// updatePrompt feeds a key to the pending action and, once submitted, runs
+// the command or saves the edit and reloads every tab.
+func (d dashboardModel) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	prompt, submitted, cmd := d.prompt.update(msg)
+	if !submitted {
+		d.prompt = prompt
+		return d, cmd
+	}
+	d.prompt = actionPrompt{}
+	if prompt.action == actionExec {
+		return d, runInDir(prompt.targets[0], prompt.path, strings.TrimSpace(prompt.input.Value()))
+	}
+	saved, selected, status, err := applyAction(d.storePath, prompt)
+	if err != nil {
+		d.status = ui.error.Render(err.Error())
+		return d, nil
+	}
+	clear(d.find.marked)
+	clear(d.previews)
+	cmd = d.setEntries(resolveRepoPaths(saved), selected)
+	d.status = ui.status.Render(status)
+	return d, tea.Batch(cmd, d.loadPreview())
+}
+
+// runInDir suspends the TUI to run command with sh in dir, waiting for enter
+// afterwards so its output can be read.
+func runInDir(name, dir, command string) tea.Cmd {
+	if command == "" {
+		return nil
+	}
+	script := "{ " + command + "\n}\n" +
+		`s=$?; printf '\n[exit %d] press enter to return to bm ' "$s" >&2; read -r _; exit "$s"`
+	c := exec.Command("sh", "-c", script)
+	c.Dir = dir
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		return execDoneMsg{name: name, command: command, err: err}
+	})
+}
+
+// openDir opens dir with the platform's file manager, without waiting for
+// it.
+func openDir(dir string) error {
+	opener := "xdg-open"
+	switch runtime.GOOS {
+	case "darwin":
+		opener = "open"
+	case "windows":
+		opener = "explorer"
+	}
+	c := exec.Command(opener, dir)
+	if err := c.Start(); err != nil {
+		return fmt.Errorf("open %s: %w", dir, err)
+	}
+	go func() { _ = c.Wait() }()
+	return nil
+}
+
+// updateMouse switches tabs on a click in the tab bar and otherwise scrolls
+// or highlights in the current tab.
+func (d dashboardModel) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if d.palette.open || d.prompt.active() || d.showHelp {
+		return d, nil
+	}
+	if msg.Y == 0 {
+		if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
+			if tab, ok := d.tabAt(msg.X); ok {
+				return d.setTab(tab)
+			}
+		}
+		return d, nil
+	}
+	msg.Y--
+	if d.tab == tabBookmarks {
+		return d.forwardFind(msg)
+	}
+	l := d.tabList()
+	if l.FilterState() == list.Filtering {
+		return d, nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		l.CursorUp()
+	case tea.MouseButtonWheelDown:
+		l.CursorDown()
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress || msg.X >= l.Width() {
+			return d, nil
+		}
+		const itemLines = 3
+		row := msg.Y - listHeaderLines
+		if row < 0 || row/itemLines >= l.Paginator.PerPage {
+			return d, nil
+		}
+		i := l.Paginator.Page*l.Paginator.PerPage + row/itemLines
+		if i >= len(l.VisibleItems()) {
+			return d, nil
+		}
+		l.Select(i)
+	default:
+		return d, nil
+	}
+	return d, d.loadPreview()
+}
+
+// tabLabels returns the tab bar labels with their counts.
+func (d dashboardModel) tabLabels() []string {
+	counts := []int{len(d.entries), len(d.tags.Items()), len(d.recent.Items()), len(d.broken.Items())}
+	labels := make([]string, len(dashTabNames))
+	for i, name := range dashTabNames {
+		labels[i] = fmt.Sprintf(" %d %s (%d) ", i+1, name, counts[i])
+	}
+	return labels
+}
+
+// tabAt returns the tab drawn at column x of the tab bar.
+func (d dashboardModel) tabAt(x int) (dashTab, bool) {
+	start := 0
+	for i, label := range d.tabLabels() {
+		end := start + ansi.StringWidth(label)
+		if x >= start && x < end {
+			return dashTab(i), true
+		}
+		start = end + 1
+	}
+	return 0, false
+}
+
+func (d dashboardModel) tabBar() string {
+	labels := d.tabLabels()
+	for i, label := range labels {
+		if dashTab(i) == d.tab {
+			labels[i] = ui.title.Render(label)
+		} else {
+			labels[i] = ui.dim.Render(label)
+		}
+	}
+	return ansi.Truncate(strings.Join(labels, " "), max(1, d.width), "…")
+}
+
+// details draws the details pane of the Tags, Recent and Broken tabs.
+func (d dashboardModel) details(width, height int) string {
+	if d.tab == tabTags {
+		it, ok := d.tags.SelectedItem().(tagItem)
+		if !ok {
+			return ""
+		}
+		return renderTagDetails(it.tag, d.entries, width, height)
+	}
+	e, ok := d.current()
+	if !ok {
+		return ""
+	}
+	return renderPreview(e, d.previews[e.Path], width, height)
+}
+
+// renderTagDetails lists the bookmarks carrying tag in a box of the given
+// outer size.
+func renderTagDetails(tag string, entries []bookmarks.Bookmark, width, height int) string {
+	inner := max(1, width-ui.previewBorder.GetHorizontalFrameSize())
+	lines := []string{ui.heading.Render(tag), ""}
+	for _, e := range filterByAnyTag(entries, []string{tag}) {
+		lines = append(lines, e.Name+"  "+ui.dim.Render(e.Path))
+	}
+	innerHeight := max(1, height-ui.previewBorder.GetVerticalFrameSize())
+	if len(lines) > innerHeight {
+		lines = lines[:innerHeight]
+	}
+	for i, l := range lines {
+		lines[i] = ansi.Truncate(l, inner, "…")
+	}
+	return ui.previewBorder.
+		Width(max(1, width-ui.previewBorder.GetHorizontalBorderSize())).
+		Height(innerHeight).
+		Render(strings.Join(lines, "\n"))
+}
+
+func (d dashboardModel) View() string {
+	var body string
+	if d.tab == tabBookmarks {
+		body = d.find.View()
+	} else {
+		l := d.tabList()
+		listView := fitWidth(l.View(), l.Width())
+		body = lipgloss.JoinHorizontal(lipgloss.Top, listView, " ", d.details(d.width-l.Width()-1, d.bodyHeight()))
+	}
+	switch {
+	case d.palette.open:
+		body = d.palette.View(d.width, d.bodyHeight())
+	case d.showHelp:
+		body = helpOverlay(d.keys.fullHelp([]string{"jump", "copy", "rename", "tags", "delete", "add", "help", "quit"}, [][2]string{
+			{"1-4", "switch tabs"},
+			{"shift+tab", "previous tab"},
+			{": ctrl+p", "command palette (edit, prune, exec, open, …)"},
+			{"/", "filter the list"},
+			{"enter", "show a tag's bookmarks (Tags tab)"},
+		}), d.width, d.bodyHeight())
+	}
+
+	footer := ui.footer.Render("1-4: tabs  •  :: commands  •  " + d.keys.shortHelp("jump", "help", "quit"))
+	if d.tab == tabBookmarks {
+		footer = d.find.footer("1-4: tabs  •  :: commands  •  " + d.keys.shortHelp("jump", "sidebar", "help", "quit"))
+	}
+	switch {
+	case d.prompt.active():
+		footer = d.prompt.View()
+	case d.tab == tabBookmarks && d.find.prompt.active():
+		footer = d.find.prompt.View()
+	case d.status != "":
+		footer = d.status + "  " + footer
+	}
+	return d.tabBar() + "\n" + body + "\n" + ansi.Truncate(footer, max(1, d.width), "…")
+}
+
+// runDashboard runs `bm tui` and returns the bookmark to jump to, if any.
+func runDashboard(storePath string, entries []bookmarks.Bookmark, visits bookmarks.Visits, keys keyMap, clip clipboardWriter) ([]string, error) {
+	m := newDashboardModel(storePath, entries, visits, time.Now())
+	m.setKeyMap(keys)
+	m.clipboard = clip
+	m.find.clipboard = clip
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithOutput(os.Stderr))
+	final, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+	dm, ok := final.(dashboardModel)
+	if !ok {
+		return nil, fmt.Errorf("unexpected model")
+	}
+	return dm.selected, nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// assertGolden compares a rendered view, without colors and trailing
+// spaces, to testdata/<name>.golden.
+func assertGolden(t *testing.T, name, view string) {
+	t.Helper()
+	lines := strings.Split(ansi.Strip(view), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	got := strings.Join(lines, "\n") + "\n"
+
+	path := filepath.Join("testdata", name+".golden")
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run go test -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Fatalf("view differs from %s (run go test -update to accept):\n%s", path, got)
+	}
+}
+
+var dashboardNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// dashboardFixture saves bookmarks where only "api" exists on disk and has
+// been visited, with their previews loaded.
+func dashboardFixture(t *testing.T) (string, dashboardModel) {
+	t.Helper()
+	storePath := filepath.Join(t.TempDir(), "bm.tsv")
+	entries := []bookmarks.Bookmark{
+		{Name: "api", Path: "/", Tags: []string{"go", "work"}, Description: "the API server"},
+		{Name: "web", Path: "/nonexistent/bm-web", Tags: []string{"work"}},
+		{Name: "notes", Path: "/nonexistent/bm-notes"},
+	}
+	if err := bookmarks.Save(storePath, entries); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	visits := bookmarks.Visits{"/": {Path: "/", Count: 3, Last: dashboardNow.Add(-2 * time.Hour)}}
+	m := newDashboardModel(storePath, entries, visits, dashboardNow)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 24})
+	next, _ = next.Update(checkBroken(entries)())
+	// "/" stands in for a project, so its preview is made up.
+	for _, msg := range []previewMsg{
+		{path: "/", data: previewData{
+			listing: []string{"cmd/", "internal/", "README.md", "go.mod"},
+			readme:  "README.md",
+			lines:   []string{"# API", "Serves the public API."},
+		}},
+		{path: "/nonexistent/bm-web", data: previewData{err: syscall.ENOENT}},
+		{path: "/nonexistent/bm-notes", data: previewData{err: syscall.ENOENT}},
+	} {
+		next, _ = next.Update(msg)
+	}
+	return storePath, next.(dashboardModel)
+}
+
+func TestDashboard_Golden(t *testing.T) {
+	_, m := dashboardFixture(t)
+	assertGolden(t, "dashboard_bookmarks", m.View())
+	for _, tab := range []struct{ key, name string }{{"2", "tags"}, {"3", "recent"}, {"4", "broken"}} {
+		next := press(m, tab.key)
+		assertGolden(t, "dashboard_"+tab.name, next.View())
+	}
+	assertGolden(t, "dashboard_palette", press(m, ":", "ed").View())
+}
+
+func TestDashboard_BrokenCheckedInBackground(t *testing.T) {
+	entries := []bookmarks.Bookmark{
+		{Name: "api", Path: t.TempDir()},
+		{Name: "web", Path: "/nonexistent/bm-web"},
+	}
+	m := newDashboardModel("", entries, nil, dashboardNow)
+	if len(m.broken.Items()) != 0 {
+		t.Fatalf("broken tab filled before the check ran")
+	}
+	next, _ := m.Update(checkBroken(entries)())
+	broken := next.(dashboardModel).broken.Items()
+	if len(broken) != 1 || broken[0].(brokenItem).b.Name != "web" {
+		t.Fatalf("broken = %v", broken)
+	}
+}
+
+func TestDashboard_Tabs(t *testing.T) {
+	_, m := dashboardFixture(t)
+
+	// enter on a tag shows its bookmarks in the Bookmarks tab.
+	next := press(m, "2", "enter").(dashboardModel)
+	if next.tab != tabBookmarks || !reflect.DeepEqual(names(next.find.entries()), []string{"api"}) {
+		t.Fatalf("tag enter: tab %d, entries %v", next.tab, names(next.find.entries()))
+	}
+
+	// enter on a recent bookmark jumps to it; broken ones cannot be jumped to.
+	next = press(m, "3", "enter").(dashboardModel)
+	if !reflect.DeepEqual(next.selected, []string{"api"}) {
+		t.Fatalf("recent enter selected %v", next.selected)
+	}
+	next = press(m, "4", "enter").(dashboardModel)
+	if next.selected != nil || !strings.Contains(ansi.Strip(next.View()), "directory is missing: /nonexistent/bm-web") {
+		t.Fatalf("broken enter selected %v", next.selected)
+	}
+
+	// shift+tab cycles backwards and clicks on the tab bar switch tabs.
+	next = press(m, "shift+tab").(dashboardModel)
+	if next.tab != tabBroken {
+		t.Fatalf("shift+tab from Bookmarks selected tab %d, want Broken", next.tab)
+	}
+	next = m
+	for range len(dashTabNames) {
+		next = press(next, "shift+tab").(dashboardModel)
+	}
+	if next.tab != tabBookmarks {
+		t.Fatalf("shift+tab did not cycle back, tab %d", next.tab)
+	}
+	x := strings.Index(ansi.Strip(m.tabBar()), "Recent")
+	clicked, _ := m.Update(tea.MouseMsg{X: x, Y: 0, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if clicked.(dashboardModel).tab != tabRecent {
+		t.Fatalf("tab bar click selected tab %d", clicked.(dashboardModel).tab)
+	}
+
+	// Keys reach the find view on the Bookmarks tab.
+	next = press(m, "enter").(dashboardModel)
+	if !reflect.DeepEqual(next.selected, []string{"api"}) {
+		t.Fatalf("find enter selected %v", next.selected)
+	}
+}
+
+func TestDashboard_Palette(t *testing.T) {
+	storePath, m := dashboardFixture(t)
+
+	next := press(m, ":", "desc", "enter", "ctrl+u", "backend", "enter").(dashboardModel)
+	if got := ansi.Strip(next.View()); !strings.Contains(got, "described api") {
+		t.Fatalf("describe status missing:\n%s", got)
+	}
+	next = press(next, ":", "prune", "enter", "y").(dashboardModel)
+	if got := ansi.Strip(next.View()); !strings.Contains(got, "pruned 2 broken bookmark(s)") {
+		t.Fatalf("prune status missing:\n%s", got)
+	}
+	if len(next.broken.Items()) != 0 || len(next.find.entries()) != 1 {
+		t.Fatalf("prune left broken %d, entries %d", len(next.broken.Items()), len(next.find.entries()))
+	}
+
+	saved, err := bookmarks.Load(storePath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(saved) != 1 || saved[0].Description != "backend" {
+		t.Fatalf("saved = %#v", saved)
+	}
+
+	// esc closes the palette without running anything.
+	next = press(next, ":", "quit", "esc").(dashboardModel)
+	if next.palette.open || next.selected != nil {
+		t.Fatalf("esc did not close the palette")
+	}
+}
+
+func TestDashboard_FindEditsRefreshTabs(t *testing.T) {
+	_, m := dashboardFixture(t)
+
+	// The find view's prompt takes the dashboard footer, keeping the height.
+	lines := strings.Split(ansi.Strip(press(m, "t").(dashboardModel).View()), "\n")
+	if len(lines) != 24 || !strings.Contains(lines[len(lines)-1], "work") {
+		t.Fatalf("tags prompt view has %d lines, footer %q", len(lines), lines[len(lines)-1])
+	}
+
+	next := press(m, "t", "ctrl+u", "h", "o", "m", "e", "enter").(dashboardModel)
+	var tags []string
+	for _, item := range next.tags.Items() {
+		tags = append(tags, item.(tagItem).tag)
+	}
+	if !reflect.DeepEqual(tags, []string{"home", "work"}) {
+		t.Fatalf("tags after retag = %v", tags)
+	}
+}
+
+func TestCmdTUI_NeedsTerminal(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "bm.tsv")
+	if err := cmdTUI(storePath, nil); err == nil || !strings.Contains(err.Error(), "needs a terminal") {
+		t.Fatalf("cmdTUI() error = %v", err)
+	}
+	if err := cmdTUI(storePath, []string{"extra"}); err == nil || !strings.Contains(err.Error(), "usage: bm tui") {
+		t.Fatalf("cmdTUI(extra) error = %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+type execResult struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	ExitCode   int    `json:"exit_code"`
+	DurationMS int64  `json:"duration_ms"`
+	Output     string `json:"output,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func cmdExec(storePath string, args []string) error {
+	const usageExec = "usage: bm exec <name|--where query> [--parallel N] [--json] -- <cmd...>"
+
+	flagArgs, command, ok := splitDashDash(args)
+	if !ok || len(command) == 0 {
+		return errors.New(usageExec)
+	}
+	positionals, err := parseArgs(flagArgs, map[string]bool{"--where": true, "--parallel": true, "--json": false})
+	if err != nil {
+		return err
+	}
+	where, hasWhere := positionals.flags["--where"]
+	if (hasWhere && len(positionals.args) != 0) || (!hasWhere && len(positionals.args) != 1) {
+		return errors.New(usageExec)
+	}
+	_, jsonOutput := positionals.flags["--json"]
+	parallel := 1
+	if v, ok := positionals.flags["--parallel"]; ok {
+		parallel, err = strconv.Atoi(v)
+		if err != nil || parallel < 1 {
+			return fmt.Errorf("--parallel expects a positive number, got %q", v)
+		}
+	}
+
+	entries, err := bookmarks.Load(storePath)
+	if err != nil {
+		return err
+	}
+	entries = resolveRepoPaths(entries)
+	var targets []bookmarks.Bookmark
+	if hasWhere {
+		q, err := bookmarks.ParseQuery(where)
+		if err != nil {
+			return err
+		}
+		targets = q.Filter(entries)
+		if len(targets) == 0 {
+			return fmt.Errorf("no bookmarks match: %s", where)
+		}
+	} else {
+		name := positionals.args[0]
+		for _, entry := range entries {
+			if entry.Name == name {
+				targets = append(targets, entry)
+				break
+			}
+		}
+		if len(targets) == 0 {
+			return fmt.Errorf("bookmark not found: %s", name)
+		}
+	}
+
+	results := runExec(targets, command, parallel, os.Stdout, os.Stderr, jsonOutput)
+
+	failed := 0
+	for _, r := range results {
+		if r.ExitCode != 0 {
+			failed++
+		}
+	}
+	if jsonOutput {
+		encoded, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(encoded))
+	} else {
+		printExecSummary(os.Stdout, results)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d command(s) failed", failed, len(results))
+	}
+	return nil
+}
+
+// runExec runs command in each target's directory, at most parallel at a
+// time. Output is streamed line by line with a "[name] " prefix, or captured
+// into the results when capture is set. Results follow the order of targets.
+func runExec(targets []bookmarks.Bookmark, command []string, parallel int, stdout, stderr io.Writer, capture bool) []execResult {
+	results := make([]execResult, len(targets))
+	width := 0
+	for _, t := range targets {
+		width = max(width, len(t.Name))
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, parallel)
+	)
+	for i, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, target bookmarks.Bookmark) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			prefix := fmt.Sprintf("[%-*s] ", width, target.Name)
+			var captured bytes.Buffer
+			var out, errOut io.Writer
+			var streams []*prefixWriter
+			if capture {
+				out, errOut = &captured, &captured
+			} else {
+				o := &prefixWriter{prefix: prefix, out: stdout, mu: &mu}
+				e := &prefixWriter{prefix: prefix, out: stderr, mu: &mu}
+				streams = append(streams, o, e)
+				out, errOut = o, e
+			}
+
+			cmd := exec.Command(command[0], command[1:]...)
+			cmd.Dir = target.Path
+			cmd.Stdout = out
+			cmd.Stderr = errOut
+			started := time.Now()
+			err := cmd.Run()
+			for _, s := range streams {
+				s.Flush()
+			}
+
+			result := execResult{
+				Name:       target.Name,
+				Path:       target.Path,
+				DurationMS: time.Since(started).Milliseconds(),
+				Output:     captured.String(),
+			}
+			var exitErr *exec.ExitError
+			switch {
+			case err == nil:
+			case errors.As(err, &exitErr):
+				result.ExitCode = exitErr.ExitCode()
+			default:
+				result.ExitCode = -1
+				result.Error = err.Error()
+				if !capture {
+					mu.Lock()
+					fmt.Fprintf(stderr, "%s%v\n", prefix, err)
+					mu.Unlock()
+				}
+			}
+			results[i] = result
+		}(i, target)
+	}
+	wg.Wait()
+	return results
+}
+
+func printExecSummary(w io.Writer, results []execResult) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nNAME\tEXIT\tDURATION\tPATH")
+	for _, r := range results {
+		exit := strconv.Itoa(r.ExitCode)
+		if r.Error != "" {
+			exit = "error"
+		}
+		duration := (time.Duration(r.DurationMS) * time.Millisecond).String()
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Name, exit, duration, r.Path)
+	}
+	_ = tw.Flush()
+}
+
+// splitDashDash splits args at the first "--".
+func splitDashDash(args []string) ([]string, []string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			return args[:i], args[i+1:], true
+		}
+	}
+	return args, nil, false
+}
+
+// prefixWriter writes complete lines to out, each prefixed, holding mu so
+// lines from concurrent commands never interleave.
+type prefixWriter struct {
+	prefix string
+	out    io.Writer
+	mu     *sync.Mutex
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(w.buf[:i+1])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes any trailing partial line.
+func (w *prefixWriter) Flush() {
+	if len(w.buf) == 0 {
+		return
+	}
+	w.writeLine(append(w.buf, '\n'))
+	w.buf = nil
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, _ = io.WriteString(w.out, w.prefix+strings.TrimRight(string(line), "\r\n")+"\n")
+}
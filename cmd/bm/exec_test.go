@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+func TestCmdExec_WhereRunsInEachDirectory(t *testing.T) {
+	root := t.TempDir()
+	storePath := filepath.Join(root, "bm.tsv")
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var entries []bookmarks.Bookmark
+	for _, name := range []string{"api", "web", "docs"} {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		tags := []string{"service"}
+		if name == "docs" {
+			tags = nil
+		}
+		entries = append(entries, bookmarks.Bookmark{Name: name, Path: dir, Tags: tags, CreatedAt: created})
+	}
+	if err := bookmarks.Save(storePath, entries); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	out, err := captureStdout(t, func() error {
+		return cmdExec(storePath, []string{"--where", "tag:service", "--parallel", "2", "--", "pwd"})
+	})
+	if err != nil {
+		t.Fatalf("cmdExec() error = %v", err)
+	}
+	for _, name := range []string{"api", "web"} {
+		if !strings.Contains(out, "["+name+"] "+filepath.Join(root, name)+"\n") {
+			t.Fatalf("missing prefixed output for %s in %q", name, out)
+		}
+	}
+	if strings.Contains(out, "[docs") {
+		t.Fatalf("untagged bookmark ran: %q", out)
+	}
+	if !strings.Contains(out, "NAME") || !strings.Contains(out, "EXIT") {
+		t.Fatalf("missing summary table in %q", out)
+	}
+}
+
+func TestCmdExec_JSONReportsExitCodes(t *testing.T) {
+	root := t.TempDir()
+	storePath := filepath.Join(root, "bm.tsv")
+	entries := []bookmarks.Bookmark{
+		{Name: "ok", Path: root, CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{Name: "gone", Path: filepath.Join(root, "missing"), CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	if err := bookmarks.Save(storePath, entries); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	out, err := captureStdout(t, func() error {
+		return cmdExec(storePath, []string{"--where", "", "--json", "--", "sh", "-c", "echo hi; exit 3"})
+	})
+	if err == nil {
+		t.Fatalf("expected failure error")
+	}
+
+	var results []execResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("json unmarshal error = %v\nstdout=%q", err, out)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %#v", results)
+	}
+	if results[0].Name != "ok" || results[0].ExitCode != 3 || results[0].Output != "hi\n" {
+		t.Fatalf("results[0] = %#v", results[0])
+	}
+	if results[1].Name != "gone" || results[1].ExitCode != -1 || results[1].Error == "" {
+		t.Fatalf("results[1] = %#v", results[1])
+	}
+}
+
+func TestCmdExec_RequiresCommand(t *testing.T) {
+	if err := cmdExec(filepath.Join(t.TempDir(), "bm.tsv"), []string{"api"}); err == nil {
+		t.Fatalf("expected usage error without -- <cmd>")
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+// fzfPreview shows the first lines of a README in the highlighted directory,
+// or its listing when there is none. fzf substitutes {2} with the quoted path.
+const fzfPreview = `for f in {2}/README* {2}/readme*; do [ -f "$f" ] && exec head -n 50 "$f"; done; ls -la {2}`
+
+// runFzf lets the user pick a bookmark with fzf, or several with multi, and
+// returns their names; none when fzf was cancelled. Each line carries the
+// name and path as hidden fields ahead of the aligned name/path/tags columns
+// that are displayed, so FZF_DEFAULT_OPTS still applies to everything else.
+func runFzf(entries []bookmarks.Bookmark, tags []string, multi bool) ([]string, error) {
+	fzfPath, err := exec.LookPath("fzf")
+	if err != nil {
+		return nil, errors.New("fzf not found in PATH")
+	}
+
+	nameWidth, pathWidth := 0, 0
+	for _, e := range entries {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(e.Name))
+		pathWidth = max(pathWidth, utf8.RuneCountInString(e.Path))
+	}
+	var input bytes.Buffer
+	for _, e := range entries {
+		fmt.Fprintf(&input, "%s\t%s\t%-*s  %-*s  %s\n",
+			e.Name, e.Path, nameWidth, e.Name, pathWidth, e.Path, strings.Join(e.Tags, ","))
+	}
+
+	prompt := "bm> "
+	if len(tags) > 0 {
+		prompt = "bm [" + strings.Join(tags, ",") + "]> "
+	}
+	args := []string{
+		"--delimiter=\t",
+		"--with-nth=3..",
+		"--prompt=" + prompt,
+		"--preview=" + fzfPreview,
+	}
+	if multi {
+		args = append(args, "--multi")
+	}
+	cmd := exec.Command(fzfPath, args...)
+	// The preview command is POSIX sh; fzf runs it with $SHELL.
+	cmd.Env = append(os.Environ(), "SHELL=/bin/sh")
+	cmd.Stdin = &input
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// 1: no match, 130: interrupted with esc or ctrl-c.
+		if errors.As(err, &exitErr) && (exitErr.ExitCode() == 1 || exitErr.ExitCode() == 130) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("fzf: %w", err)
+	}
+	var names []string
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		if name, _, _ := strings.Cut(line, "\t"); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
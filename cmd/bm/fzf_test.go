@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+// stubFzf installs an fzf stand-in that records its arguments, input and
+// FZF_DEFAULT_OPTS, and selects the input line containing $STUB_PICK.
+func stubFzf(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	script := `#!/bin/sh
+printf '%s\n' "$@" > "$STUB_DIR/args"
+printf '%s' "$FZF_DEFAULT_OPTS" > "$STUB_DIR/opts"
+tee "$STUB_DIR/input" | grep -F "$STUB_PICK" | head -n 1 | grep . || exit 130
+`
+	if err := os.WriteFile(filepath.Join(dir, "fzf"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write stub: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("STUB_DIR", dir)
+	return dir
+}
+
+func TestCmdFind_Fzf(t *testing.T) {
+	stub := stubFzf(t)
+	t.Setenv("FZF_DEFAULT_OPTS", "--height 40%")
+	storePath := filepath.Join(t.TempDir(), "bm.tsv")
+	if err := bookmarks.Save(storePath, promptEntries()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	t.Setenv("STUB_PICK", "/src/web")
+	out, err := captureStdout(t, func() error { return cmdFind(storePath, []string{"--fzf"}) })
+	if err != nil {
+		t.Fatalf("cmdFind(--fzf) error = %v", err)
+	}
+	if out != "bm go 'web'\n" {
+		t.Fatalf("cmdFind(--fzf) = %q", out)
+	}
+
+	input, _ := os.ReadFile(filepath.Join(stub, "input"))
+	if !strings.Contains(string(input), "api\t/src/api\tapi      /src/api      work\n") {
+		t.Fatalf("fzf input = %q", input)
+	}
+	args, _ := os.ReadFile(filepath.Join(stub, "args"))
+	if !strings.Contains(string(args), "--with-nth=3..") || !strings.Contains(string(args), "--preview=") {
+		t.Fatalf("fzf args = %q", args)
+	}
+	if opts, _ := os.ReadFile(filepath.Join(stub, "opts")); string(opts) != "--height 40%" {
+		t.Fatalf("FZF_DEFAULT_OPTS = %q", opts)
+	}
+
+	// Cancelling fzf selects nothing.
+	t.Setenv("STUB_PICK", "no such line")
+	out, err = captureStdout(t, func() error { return cmdFind(storePath, []string{"--fzf"}) })
+	if err != nil || out != "" {
+		t.Fatalf("cancelled cmdFind(--fzf) = %q, %v", out, err)
+	}
+}
+
+func TestCmdFind_FinderConfig(t *testing.T) {
+	stubFzf(t)
+	t.Setenv("STUB_PICK", "/src/api-old")
+	writeConfig(t, "finder = fzf\n")
+	storePath := filepath.Join(t.TempDir(), "bm.tsv")
+	if err := bookmarks.Save(storePath, promptEntries()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	out, err := captureStdout(t, func() error { return cmdFind(storePath, nil) })
+	if err != nil {
+		t.Fatalf("cmdFind() error = %v", err)
+	}
+	if out != "bm go 'api-old'\n" {
+		t.Fatalf("cmdFind() = %q", out)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyAction is a TUI command that can be bound to keys with `key.<action>`
+// in the config file. short labels the footer; help the `?` overlay.
+type keyAction struct {
+	name  string
+	keys  []string
+	short string
+	help  string
+}
+
+// keyActions are the bindable actions with their default keys, in the order
+// help lists them.
+var keyActions = []keyAction{
+	{name: "jump", keys: []string{"enter"}, short: "jump", help: "jump to the bookmark"},
+	{name: "copy", keys: []string{"c"}, short: "copy", help: "copy the path to the clipboard"},
+	{name: "preview", keys: []string{"p"}, short: "preview", help: "show or hide the preview pane"},
+	{name: "mark", keys: []string{" "}, short: "mark", help: "mark or unmark the bookmark"},
+	{name: "mark-all", keys: []string{"*"}, short: "mark all", help: "mark every visible bookmark, or clear the marks"},
+	{name: "rename", keys: []string{"r"}, short: "rename", help: "rename the bookmark"},
+	{name: "tags", keys: []string{"t"}, short: "edit tags", help: "edit the tags of the marked bookmarks"},
+	{name: "delete", keys: []string{"d"}, short: "delete", help: "delete the marked bookmarks"},
+	{name: "add", keys: []string{"a"}, short: "add cwd", help: "bookmark the current directory"},
+	{name: "sidebar", keys: []string{"tab"}, short: "tags", help: "focus the tag sidebar"},
+	{name: "toggle-sidebar", keys: []string{"T"}, short: "sidebar", help: "show or hide the tag sidebar"},
+	{name: "help", keys: []string{"?"}, short: "help", help: "show this help"},
+	{name: "quit", keys: []string{"q"}, short: "quit", help: "quit without choosing"},
+}
+
+// keyMap resolves key presses to action names.
+type keyMap struct {
+	keys  map[string][]string
+	byKey map[string]string
+}
+
+// reservedKeys are the keys the TUIs keep for navigation and filtering: the
+// list's own cursor and filter bindings plus the table's sort keys.
+func reservedKeys() map[string]string {
+	reserved := map[string]string{}
+	lk := list.DefaultKeyMap()
+	for _, b := range []key.Binding{lk.CursorUp, lk.CursorDown, lk.GoToStart, lk.GoToEnd, lk.Filter, lk.ClearFilter, lk.ForceQuit} {
+		desc := b.Help().Desc
+		if desc == "" {
+			desc = "quit"
+		}
+		for _, k := range b.Keys() {
+			reserved[k] = desc
+		}
+	}
+	for _, k := range "0123456789" {
+		reserved[string(k)] = "sort"
+	}
+	return reserved
+}
+
+func defaultKeyMap() keyMap {
+	k, _ := newKeyMap(nil)
+	return k
+}
+
+// newKeyMap applies config overrides (action name to comma-separated keys)
+// to the default bindings. Unknown actions, keys bound to two actions and
+// keys the list needs for navigation or filtering are errors.
+func newKeyMap(overrides map[string]string) (keyMap, error) {
+	k := keyMap{keys: map[string][]string{}, byKey: map[string]string{}}
+	for _, a := range keyActions {
+		k.keys[a.name] = a.keys
+	}
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := k.keys[name]; !ok {
+			return keyMap{}, fmt.Errorf("key.%s: unknown action", name)
+		}
+		keys := parseKeys(overrides[name])
+		if len(keys) == 0 {
+			return keyMap{}, fmt.Errorf("key.%s: no keys", name)
+		}
+		k.keys[name] = keys
+	}
+
+	reserved := reservedKeys()
+	for _, a := range keyActions {
+		for _, key := range k.keys[a.name] {
+			if desc, ok := reserved[key]; ok {
+				return keyMap{}, fmt.Errorf("key.%s: %s is reserved for %s", a.name, formatKey(key), desc)
+			}
+			if other, ok := k.byKey[key]; ok {
+				return keyMap{}, fmt.Errorf("key.%s: %s is already bound to %s", a.name, formatKey(key), other)
+			}
+			k.byKey[key] = a.name
+		}
+	}
+	return k, nil
+}
+
+// parseKeys splits a comma-separated key list; "space" and "comma" name the
+// keys that cannot be written literally.
+func parseKeys(value string) []string {
+	var keys []string
+	for _, k := range strings.Split(value, ",") {
+		switch k = strings.TrimSpace(k); k {
+		case "":
+		case "space":
+			keys = append(keys, " ")
+		case "comma":
+			keys = append(keys, ",")
+		default:
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func formatKey(k string) string {
+	switch k {
+	case " ":
+		return "space"
+	case ",":
+		return "comma"
+	}
+	return k
+}
+
+// action returns the action bound to msg, or "".
+func (k keyMap) action(msg tea.KeyMsg) string {
+	return k.byKey[msg.String()]
+}
+
+// label returns the keys bound to an action for display, e.g. "c/y".
+func (k keyMap) label(action string) string {
+	keys := make([]string, 0, len(k.keys[action]))
+	for _, key := range k.keys[action] {
+		keys = append(keys, formatKey(key))
+	}
+	return strings.Join(keys, "/")
+}
+
+// shortHelp renders a footer like "enter: jump  •  c: copy" for actions.
+func (k keyMap) shortHelp(actions ...string) string {
+	parts := make([]string, 0, len(actions))
+	for _, name := range actions {
+		for _, a := range keyActions {
+			if a.name == name {
+				parts = append(parts, k.label(name)+": "+a.short)
+			}
+		}
+	}
+	return strings.Join(parts, "  •  ")
+}
+
+// fullHelp returns "keys  description" lines for actions followed by the
+// fixed bindings in extra, aligned for the help overlay.
+func (k keyMap) fullHelp(actions []string, extra [][2]string) []string {
+	var rows [][2]string
+	for _, a := range keyActions {
+		if slices.Contains(actions, a.name) {
+			rows = append(rows, [2]string{k.label(a.name), a.help})
+		}
+	}
+	rows = append(rows, extra...)
+	width := 0
+	for _, r := range rows {
+		width = max(width, len([]rune(r[0])))
+	}
+	lines := make([]string, 0, len(rows))
+	for _, r := range rows {
+		lines = append(lines, r[0]+strings.Repeat(" ", width-len([]rune(r[0]))+2)+r[1])
+	}
+	return lines
+}
+
+// unbind removes keys taken by actions from a navigation binding, so e.g.
+// "d" deletes instead of paging.
+func (k keyMap) unbind(b *key.Binding) {
+	var keys []string
+	for _, key := range b.Keys() {
+		if _, taken := k.byKey[key]; !taken {
+			keys = append(keys, key)
+		}
+	}
+	b.SetKeys(keys...)
+}
+
+// helpOverlay draws the help lines in a box centered in width by height.
+func helpOverlay(lines []string, width, height int) string {
+	box := ui.helpBorder.Render(ui.heading.Render("Keys") + "\n\n" + strings.Join(lines, "\n") + "\n\n" + ui.dim.Render("press any key to close"))
+	if width <= 0 {
+		return box
+	}
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, lipgloss.NewStyle().MaxWidth(width).Render(box))
+}
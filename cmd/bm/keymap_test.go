@@ -0,0 +1,162 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+func TestNewKeyMap(t *testing.T) {
+	k, err := newKeyMap(map[string]string{"copy": "y, ctrl+y", "mark": "space", "quit": "x"})
+	if err != nil {
+		t.Fatalf("newKeyMap() error = %v", err)
+	}
+	for key, want := range map[string]string{"y": "copy", "ctrl+y": "copy", " ": "mark", "x": "quit", "c": "", "q": "", "enter": "jump"} {
+		if got := k.byKey[key]; got != want {
+			t.Fatalf("key %q = %q, want %q", key, got, want)
+		}
+	}
+	if got := k.shortHelp("copy", "mark"); got != "y/ctrl+y: copy  •  space: mark" {
+		t.Fatalf("shortHelp() = %q", got)
+	}
+}
+
+func TestNewKeyMap_Conflicts(t *testing.T) {
+	tests := []struct {
+		overrides map[string]string
+		wantErr   string
+	}{
+		{overrides: map[string]string{"copy": "/"}, wantErr: "key.copy: / is reserved for filter"},
+		{overrides: map[string]string{"rename": "esc"}, wantErr: "esc is reserved for clear filter"},
+		{overrides: map[string]string{"preview": "j"}, wantErr: "j is reserved for down"},
+		{overrides: map[string]string{"add": "1"}, wantErr: "1 is reserved for sort"},
+		{overrides: map[string]string{"copy": "r"}, wantErr: "key.rename: r is already bound to copy"},
+		{overrides: map[string]string{"open": "o"}, wantErr: "key.open: unknown action"},
+		{overrides: map[string]string{"copy": " , "}, wantErr: "key.copy: no keys"},
+	}
+	for _, tt := range tests {
+		_, err := newKeyMap(tt.overrides)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Fatalf("newKeyMap(%v) error = %v, want %q", tt.overrides, err, tt.wantErr)
+		}
+	}
+	// Swapping two actions' keys is fine.
+	if _, err := newKeyMap(map[string]string{"copy": "r", "rename": "c"}); err != nil {
+		t.Fatalf("newKeyMap(swap) error = %v", err)
+	}
+}
+
+func TestFindModel_CustomKeysAndHelp(t *testing.T) {
+	keys, err := newKeyMap(map[string]string{"delete": "x", "help": "h"})
+	if err != nil {
+		t.Fatalf("newKeyMap() error = %v", err)
+	}
+	fm := newFindModel("", promptEntries(), "bm find", nil)
+	fm.setKeyMap(keys)
+	var m tea.Model = fm
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 70, Height: 30})
+
+	// "h" left the list's previous-page keys for the help action.
+	for _, k := range m.(findModel).list.KeyMap.PrevPage.Keys() {
+		if k == "h" {
+			t.Fatalf("h still pages back")
+		}
+	}
+	m = press(m, "h")
+	view := ansi.Strip(m.View())
+	if !regexp.MustCompile(`x +delete the marked bookmarks`).MatchString(view) || !regexp.MustCompile(`h +show this help`).MatchString(view) {
+		t.Fatalf("help overlay:\n%s", view)
+	}
+	m = press(m, "z")
+	if strings.Contains(ansi.Strip(m.View()), "show this help") {
+		t.Fatalf("help overlay still shown")
+	}
+	m = press(m, "x")
+	if !m.(findModel).prompt.active() || m.(findModel).prompt.action != actionDelete {
+		t.Fatalf("x did not start a delete")
+	}
+}
+
+func TestFindModel_Mouse(t *testing.T) {
+	var m tea.Model = newFindModel("", promptEntries(), "bm find", nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 70, Height: 30})
+	click := func(m tea.Model, x, y int) (tea.Model, tea.Cmd) {
+		return m.Update(tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	}
+
+	// Items are three lines apart below the title and status bar.
+	m, _ = click(m, 5, listHeaderLines+2*3)
+	if got := m.(findModel).highlighted(); got != "web" {
+		t.Fatalf("clicked %q, want web", got)
+	}
+	m, _ = m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelUp, Action: tea.MouseActionPress})
+	if got := m.(findModel).highlighted(); got != "api-old" {
+		t.Fatalf("after wheel up %q", got)
+	}
+	m, cmd := click(m, 5, listHeaderLines+1)
+	if cmd != nil && m.(findModel).selected != nil {
+		t.Fatalf("single click jumped")
+	}
+	m, cmd = click(m, 5, listHeaderLines+1)
+	if got := m.(findModel).selected; len(got) != 1 || got[0] != "api" || cmd == nil {
+		t.Fatalf("double-click selected %v", got)
+	}
+}
+
+func TestFindModel_MouseSidebar(t *testing.T) {
+	var m tea.Model = newFindModel("", sidebarEntries(), "bm find", nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 70, Height: 30})
+	m = press(m, "T")
+	// Border, heading, then go, js, work.
+	m, _ = m.Update(tea.MouseMsg{X: 3, Y: 3, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if got := names(m.(findModel).entries()); len(got) != 1 || got[0] != "web" {
+		t.Fatalf("after clicking js = %v", got)
+	}
+}
+
+func TestTableModel_Mouse(t *testing.T) {
+	var entries []bookmarks.Bookmark
+	for _, name := range strings.Fields("a b c d e f g h i j k l") {
+		entries = append(entries, bookmarks.Bookmark{Name: name, Path: "/src/" + name})
+	}
+	var m tea.Model = newTableModel("", entries, "bm table", mustColumns(t, "name", "path"))
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 60, Height: 8})
+	click := func(m tea.Model, y int) tea.Model {
+		m, _ = m.Update(tea.MouseMsg{X: 2, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+		return m
+	}
+	selected := func(m tea.Model) string {
+		e, _ := m.(tableModel).selectedEntry()
+		return e.Name
+	}
+
+	// The title line, then the column headers.
+	m = click(m, 3)
+	if got := selected(m); got != "b" {
+		t.Fatalf("clicked %q, want b", got)
+	}
+	m = click(m, 1)
+	if got := selected(m); got != "b" {
+		t.Fatalf("header click moved the cursor to %q", got)
+	}
+
+	// Scroll down so rows no longer start at "a"; clicks still map.
+	for range 8 {
+		m, _ = m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+	}
+	view := strings.Split(ansi.Strip(m.View()), "\n")
+	row := strings.Fields(view[3])[0]
+	m = click(m, 3)
+	if got := selected(m); got != row {
+		t.Fatalf("clicked %q, want %q:\n%s", got, row, strings.Join(view, "\n"))
+	}
+	m = click(m, 3)
+	if got := m.(tableModel).selected; len(got) != 1 || got[0] != row {
+		t.Fatalf("double-click selected %v", got)
+	}
+}
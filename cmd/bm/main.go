@@ -4,12 +4,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/x/term"
+
 	"github.com/navio/bookmarks/internal/bookmarks"
 )
 
@@ -60,10 +65,14 @@ func run(args []string) error {
 		return cmdFind(storePath, rest[1:])
 	case "table":
 		return cmdTable(storePath, rest[1:])
+	case "tui":
+		return cmdTUI(storePath, rest[1:])
 	case "path":
 		return cmdPath(storePath, rest[1:])
 	case "go":
 		return cmdGo(storePath, rest[1:])
+	case "copy":
+		return cmdCopy(storePath, rest[1:])
 	case "init":
 		return cmdInit(rest[1:])
 	case "update":
@@ -72,6 +81,30 @@ func run(args []string) error {
 		return cmdRemove(storePath, rest[1:])
 	case "shell":
 		return cmdShell(rest[1:])
+	case "sync":
+		return cmdSync(storePath, rest[1:])
+	case "merge":
+		return cmdMerge(rest[1:])
+	case "note":
+		return cmdNote(storePath, rest[1:])
+	case "exec":
+		return cmdExec(storePath, rest[1:])
+	case "status":
+		return cmdStatus(storePath, rest[1:])
+	case "scan":
+		return cmdScan(storePath, rest[1:])
+	case "retag":
+		return cmdRetag(storePath, rest[1:])
+	case "suggest":
+		return cmdSuggest(storePath, rest[1:])
+	case "record":
+		return cmdRecord(storePath, rest[1:])
+	case "hook":
+		return cmdHook(storePath, rest[1:])
+	case "trust":
+		return cmdTrust(storePath, rest[1:], true)
+	case "untrust":
+		return cmdTrust(storePath, rest[1:], false)
 	case "help":
 		fmt.Println(usage())
 		return nil
@@ -81,7 +114,7 @@ func run(args []string) error {
 }
 
 func cmdAdd(storePath string, args []string) error {
-	positionals, err := parseArgs(args, map[string]bool{"--tags": true, "-f": false, "--force": false})
+	positionals, err := parseArgs(args, map[string]bool{"--tags": true, "--desc": true, "--hook": true, "--repo-relative": false, "-f": false, "--force": false})
 	if err != nil {
 		return err
 	}
@@ -93,9 +126,18 @@ func cmdAdd(storePath string, args []string) error {
 		tagsInput = value
 		hasTags = true
 	}
+	desc, hasDesc := positionals.flags["--desc"]
+	if hasDesc {
+		if strings.Contains(desc, "\n") {
+			return errors.New("description cannot contain newlines")
+		}
+		desc = strings.TrimSpace(desc)
+	}
+	hook, hasHook := positionals.flags["--hook"]
 	_, forceShort := positionals.flags["-f"]
 	_, forceLong := positionals.flags["--force"]
 	force := forceShort || forceLong
+	_, repoMode := positionals.flags["--repo-relative"]
 
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -103,7 +145,7 @@ func cmdAdd(storePath string, args []string) error {
 	}
 
 	if len(positionals.args) > 2 {
-		return fmt.Errorf("usage: bm add [name] [path] [--tags a,b,c] [-f|--force]")
+		return fmt.Errorf("usage: bm add [name] [path] [--tags a,b,c] [--desc text] [--hook cmd] [--repo-relative] [-f|--force]")
 	}
 
 	name := ""
@@ -128,11 +170,23 @@ func cmdAdd(storePath string, args []string) error {
 	if err != nil {
 		return err
 	}
+	var repo, repoPath string
+	if repoMode {
+		repo, repoPath, err = repoRelative(resolvedPath)
+		if err != nil {
+			return err
+		}
+	}
 
 	entries, err := bookmarks.Load(storePath)
 	if err != nil {
 		return err
 	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	ruleTags := bookmarks.ApplyRules(cfg.Rules, resolvedPath)
 
 	for i := range entries {
 		if entries[i].Name != name {
@@ -142,38 +196,53 @@ func cmdAdd(storePath string, args []string) error {
 			return fmt.Errorf("bookmark already exists: %s", name)
 		}
 		entries[i].Path = resolvedPath
+		entries[i].Repo, entries[i].RepoPath = repo, repoPath
 		if hasTags {
 			entries[i].Tags = bookmarks.NormalizeTags(tagsInput)
 		}
-		return bookmarks.Save(storePath, entries)
+		entries[i].Tags = bookmarks.AddTags(entries[i].Tags, ruleTags)
+		if hasDesc {
+			entries[i].Description = desc
+		}
+		if hasHook {
+			entries[i].Hook = hook
+		}
+		entries[i].UpdatedAt = time.Now().UTC()
+		return saveStore(storePath, entries, "update "+name)
 	}
 
 	entry := bookmarks.Bookmark{
-		Name:      name,
-		Path:      resolvedPath,
-		Tags:      nil,
-		CreatedAt: time.Now().UTC(),
+		Name:        name,
+		Path:        resolvedPath,
+		Tags:        nil,
+		CreatedAt:   time.Now().UTC(),
+		Description: desc,
+		Hook:        hook,
+		Repo:        repo,
+		RepoPath:    repoPath,
 	}
 	if hasTags {
 		entry.Tags = bookmarks.NormalizeTags(tagsInput)
 	}
+	entry.Tags = bookmarks.AddTags(entry.Tags, ruleTags)
 	entries = append(entries, entry)
 
-	if err := bookmarks.Save(storePath, entries); err != nil {
+	if err := saveStore(storePath, entries, "add "+name); err != nil {
 		return err
 	}
 	return nil
 }
 
 func cmdList(storePath string, args []string) error {
-	positionals, err := parseArgs(args, map[string]bool{"--json": false, "--tag": true})
+	positionals, err := parseArgs(args, map[string]bool{"--json": false, "--long": false, "--tag": true})
 	if err != nil {
 		return err
 	}
 	if len(positionals.args) != 0 {
-		return errors.New("usage: bm ls [--json] [--tag x]")
+		return errors.New("usage: bm ls [--json] [--long] [--tag x]")
 	}
 	_, jsonOutput := positionals.flags["--json"]
+	_, long := positionals.flags["--long"]
 	tagFilter := positionals.flags["--tag"]
 
 	entries, err := bookmarks.Load(storePath)
@@ -196,10 +265,14 @@ func cmdList(storePath string, args []string) error {
 		payload := make([]map[string]any, 0, len(filtered))
 		for _, entry := range filtered {
 			payload = append(payload, map[string]any{
-				"name":       entry.Name,
-				"path":       entry.Path,
-				"tags":       entry.Tags,
-				"created_at": entry.CreatedAt.Format(time.RFC3339),
+				"name":        entry.Name,
+				"path":        entry.Path,
+				"tags":        entry.Tags,
+				"created_at":  entry.CreatedAt.Format(time.RFC3339),
+				"description": entry.Description,
+				"notes":       entry.Notes,
+				"mode":        bookmarkMode(entry),
+				"repo_path":   entry.RepoPath,
 			})
 		}
 		encoded, err := json.MarshalIndent(payload, "", "  ")
@@ -211,12 +284,16 @@ func cmdList(storePath string, args []string) error {
 	}
 
 	for _, entry := range filtered {
-		fmt.Printf("%s\t%s\t%s\t%s\n",
+		fields := []string{
 			entry.Name,
 			entry.Path,
 			strings.Join(entry.Tags, ","),
 			entry.CreatedAt.Format(time.RFC3339),
-		)
+		}
+		if long {
+			fields = append(fields, bookmarkMode(entry))
+		}
+		fmt.Println(strings.Join(fields, "\t"))
 	}
 	return nil
 }
@@ -236,6 +313,29 @@ func cmdTags(storePath string, args []string) error {
 		return err
 	}
 
+	tags, counts := tagCounts(entries)
+	if jsonOutput {
+		payload := make([]map[string]any, 0, len(tags))
+		for _, t := range tags {
+			payload = append(payload, map[string]any{"tag": t, "count": counts[t]})
+		}
+		encoded, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(encoded))
+		return nil
+	}
+
+	for _, t := range tags {
+		fmt.Printf("%s\t%d\n", t, counts[t])
+	}
+	return nil
+}
+
+// tagCounts returns the tags used by entries, sorted, and how many bookmarks
+// carry each.
+func tagCounts(entries []bookmarks.Bookmark) ([]string, map[string]int) {
 	counts := map[string]int{}
 	for _, e := range entries {
 		for _, tag := range e.Tags {
@@ -252,33 +352,129 @@ func cmdTags(storePath string, args []string) error {
 		tags = append(tags, t)
 	}
 	sort.Strings(tags)
+	return tags, counts
+}
 
-	if jsonOutput {
-		payload := make([]map[string]any, 0, len(tags))
-		for _, t := range tags {
-			payload = append(payload, map[string]any{"tag": t, "count": counts[t]})
-		}
-		encoded, err := json.MarshalIndent(payload, "", "  ")
+func cmdFind(storePath string, args []string) error {
+	positionals, err := parseArgs(args, map[string]bool{"--tag": true, "--tags": true, "--query": true, "--no-tui": false, "--fzf": false, "--multi": false, "--paths": false, "--print": true, "--spawn-shell": false, "--print0": false, "-0": false, "--theme": true})
+	if err != nil {
+		return err
+	}
+	if len(positionals.args) != 0 {
+		return errors.New("usage: bm find [--tag x] [--tags a,b,c] [--query q] [--no-tui] [--fzf] [--multi] [--paths] [--print path|name|cd|go] [--spawn-shell] [-0|--print0] [--theme name]")
+	}
+	output, err := parseSelectionOutput(positionals.flags)
+	if err != nil {
+		return err
+	}
+	query, hasQuery := positionals.flags["--query"]
+	_, noTUI := positionals.flags["--no-tui"]
+	_, useFzf := positionals.flags["--fzf"]
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if !useFzf && cfg.Finder == "fzf" {
+		// A configured fzf finder is only used when it is installed.
+		_, err := exec.LookPath("fzf")
+		useFzf = err == nil
+	}
+	keys, err := newKeyMap(cfg.Keys)
+	if err != nil {
+		return err
+	}
+	themeName := themeFlag(positionals.flags, cfg)
+	if err := checkTheme(themeName); err != nil {
+		return err
+	}
+
+	entries, err := bookmarks.Load(storePath)
+	if err != nil {
+		return err
+	}
+	// The TUI starts from every bookmark (or every query match) so its tag
+	// sidebar can widen the --tag filter; the other pickers only get matches.
+	pool := resolveRepoPaths(entries)
+	if hasQuery {
+		pool, err = matchQuery(pool, query)
 		if err != nil {
 			return err
 		}
-		fmt.Println(string(encoded))
-		return nil
+	}
+	tags := parseTagFilters(positionals.flags)
+	entries = filterByAnyTag(pool, tags)
+
+	if hasQuery {
+		switch len(entries) {
+		case 0:
+			return fmt.Errorf("no bookmarks match %q", query)
+		case 1:
+			return output.deliver(entries, []string{entries[0].Name})
+		}
 	}
 
-	for _, t := range tags {
-		fmt.Printf("%s\t%d\n", t, counts[t])
+	var selected []string
+	switch {
+	case noTUI:
+		selected, err = promptSelect(entries, os.Stdin, os.Stderr, !hasQuery, output.multi)
+	case useFzf:
+		selected, err = runFzf(entries, tags, output.multi)
+	case !hasTerminal():
+		selected, err = promptSelect(entries, os.Stdin, os.Stderr, !hasQuery, output.multi)
+	default:
+		if err := setupTheme(themeName); err != nil {
+			return err
+		}
+		selected, err = runFindTUI(storePath, pool, "bm find", tags, keys, newClipboardWriter(cfg.Clipboard), output.multi)
 	}
-	return nil
+	if err != nil {
+		return err
+	}
+	return output.deliver(pool, selected)
 }
 
-func cmdFind(storePath string, args []string) error {
-	positionals, err := parseArgs(args, map[string]bool{"--tag": true, "--tags": true})
+func cmdTable(storePath string, args []string) error {
+	positionals, err := parseArgs(args, map[string]bool{"--tag": true, "--tags": true, "--git": false, "--columns": true, "--multi": false, "--paths": false, "--print": true, "--spawn-shell": false, "--print0": false, "-0": false, "--theme": true})
 	if err != nil {
 		return err
 	}
 	if len(positionals.args) != 0 {
-		return errors.New("usage: bm find [--tag x] [--tags a,b,c]")
+		return errors.New("usage: bm table [--tag x] [--tags a,b,c] [--git] [--columns a,b,c] [--multi] [--paths] [--print path|name|cd|go] [--spawn-shell] [-0|--print0] [--theme name]")
+	}
+	output, err := parseSelectionOutput(positionals.flags)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	keys := defaultTableColumns
+	if len(cfg.TableColumns) > 0 {
+		keys = cfg.TableColumns
+	}
+	if v, ok := positionals.flags["--columns"]; ok {
+		keys = nil
+		for _, key := range strings.Split(v, ",") {
+			if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	if _, ok := positionals.flags["--git"]; ok && !slices.Contains(keys, "git") {
+		keys = append(slices.Clone(keys), "git")
+	}
+	columns, err := lookupColumns(keys)
+	if err != nil {
+		return err
+	}
+	keyMap, err := newKeyMap(cfg.Keys)
+	if err != nil {
+		return err
+	}
+	if err := checkTheme(themeFlag(positionals.flags, cfg)); err != nil {
+		return err
 	}
 
 	entries, err := bookmarks.Load(storePath)
@@ -286,40 +482,188 @@ func cmdFind(storePath string, args []string) error {
 		return err
 	}
 	tags := parseTagFilters(positionals.flags)
-	entries = filterByAnyTag(entries, tags)
+	entries = filterByAnyTag(resolveRepoPaths(entries), tags)
 
-	selected, err := runFindTUI(entries, "bm find", tags)
-	if err != nil {
+	if err := setupTheme(themeFlag(positionals.flags, cfg)); err != nil {
 		return err
 	}
-	if strings.TrimSpace(selected) != "" {
-		fmt.Println(formatGoCommand(selected))
+	selected, err := runTableTUI(storePath, entries, "bm table", columns, keyMap, newClipboardWriter(cfg.Clipboard), output.multi)
+	if err != nil {
+		return err
 	}
-	return nil
+	return output.deliver(entries, selected)
 }
 
-func cmdTable(storePath string, args []string) error {
-	positionals, err := parseArgs(args, map[string]bool{"--tag": true, "--tags": true})
+func cmdTUI(storePath string, args []string) error {
+	positionals, err := parseArgs(args, map[string]bool{"--paths": false, "--print": true, "--spawn-shell": false, "--theme": true})
 	if err != nil {
 		return err
 	}
 	if len(positionals.args) != 0 {
-		return errors.New("usage: bm table [--tag x] [--tags a,b,c]")
+		return errors.New("usage: bm tui [--paths] [--print path|name|cd|go] [--spawn-shell] [--theme name]")
+	}
+	output, err := parseSelectionOutput(positionals.flags)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	keys, err := newKeyMap(cfg.Keys)
+	if err != nil {
+		return err
+	}
+	themeName := themeFlag(positionals.flags, cfg)
+	if err := checkTheme(themeName); err != nil {
+		return err
+	}
+	if !hasTerminal() {
+		return errors.New("bm tui needs a terminal; use bm find --no-tui instead")
 	}
 
 	entries, err := bookmarks.Load(storePath)
 	if err != nil {
 		return err
 	}
-	tags := parseTagFilters(positionals.flags)
-	entries = filterByAnyTag(entries, tags)
+	entries = resolveRepoPaths(entries)
+	visitsPath, err := bookmarks.DefaultVisitsPath()
+	if err != nil {
+		return err
+	}
+	visits, err := bookmarks.LoadVisits(visitsPath)
+	if err != nil {
+		return err
+	}
 
-	selected, err := runTableTUI(entries, "bm table")
+	if err := setupTheme(themeName); err != nil {
+		return err
+	}
+	selected, err := runDashboard(storePath, entries, visits, keys, newClipboardWriter(cfg.Clipboard))
 	if err != nil {
 		return err
 	}
-	if strings.TrimSpace(selected) != "" {
-		fmt.Println(formatGoCommand(selected))
+	// The store may have been edited in the dashboard; print from the saved
+	// version.
+	saved, err := bookmarks.Load(storePath)
+	if err != nil {
+		return err
+	}
+	return output.deliver(resolveRepoPaths(saved), selected)
+}
+
+// selectionOutput is how find, table and tui deliver the chosen bookmarks.
+// A single choice prints a `bm go` command by default and several print
+// their names; --print picks path, name, cd or go for every choice, one per
+// line or NUL-terminated. With spawnShell a shell is started in the chosen
+// directory instead, so the pickers work without the shell wrapper.
+type selectionOutput struct {
+	multi      bool
+	mode       string
+	print0     bool
+	spawnShell bool
+}
+
+var printModes = []string{"path", "name", "cd", "go"}
+
+func parseSelectionOutput(flags map[string]string) (selectionOutput, error) {
+	_, multi := flags["--multi"]
+	_, paths := flags["--paths"]
+	_, print0 := flags["--print0"]
+	_, short := flags["-0"]
+	_, spawn := flags["--spawn-shell"]
+	mode, hasMode := flags["--print"]
+	out := selectionOutput{multi: multi, mode: strings.ToLower(strings.TrimSpace(mode)), print0: print0 || short, spawnShell: spawn}
+	switch {
+	case hasMode && !slices.Contains(printModes, out.mode):
+		return out, fmt.Errorf("unknown --print mode: %s (expected path, name, cd or go)", mode)
+	case hasMode && paths:
+		return out, errors.New("--paths is short for --print path; use one of them")
+	case spawn && (hasMode || paths || out.print0):
+		return out, errors.New("--spawn-shell does not print anything; drop --print, --paths and --print0")
+	case spawn && multi:
+		return out, errors.New("--spawn-shell starts a shell in a single bookmark; drop --multi")
+	}
+	if paths {
+		out.mode = "path"
+	}
+	return out, nil
+}
+
+func (o selectionOutput) print(w io.Writer, entries []bookmarks.Bookmark, names []string) {
+	sep := "\n"
+	if o.print0 {
+		sep = "\x00"
+	}
+	mode := o.mode
+	if mode == "" {
+		mode = "go"
+		if o.multi {
+			mode = "name"
+		}
+	}
+	for _, name := range names {
+		switch mode {
+		case "path", "cd":
+			i := findEntry(entries, name)
+			if i < 0 {
+				continue
+			}
+			if mode == "cd" {
+				fmt.Fprint(w, "cd -- "+shellQuote(entries[i].Path)+sep)
+			} else {
+				fmt.Fprint(w, entries[i].Path+sep)
+			}
+		case "name":
+			fmt.Fprint(w, name+sep)
+		default:
+			fmt.Fprint(w, formatGoCommand(name)+sep)
+		}
+	}
+}
+
+// deliver prints the chosen bookmarks to stdout, or starts a shell in the
+// chosen one with --spawn-shell.
+func (o selectionOutput) deliver(entries []bookmarks.Bookmark, names []string) error {
+	if !o.spawnShell {
+		o.print(os.Stdout, entries, names)
+		if o.mode == "" && !o.multi && len(names) > 0 && term.IsTerminal(os.Stdout.Fd()) {
+			// Nothing captured the `bm go` command: the shell wrapper is
+			// not installed.
+			fmt.Fprintln(os.Stderr, "bm: run `bm init` to jump directly, or use --print cd or --spawn-shell")
+		}
+		return nil
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	i := findEntry(entries, names[0])
+	if i < 0 {
+		return fmt.Errorf("bookmark not found: %s", names[0])
+	}
+	return spawnShell(entries[i].Path)
+}
+
+// spawnShell runs $SHELL (or /bin/sh) in dir and waits for it to exit. The
+// shell's own exit status is not an error of bm's.
+var spawnShell = func(dir string) error {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "bm: starting %s in %s (exit to return)\n", shell, dir)
+	cmd := exec.Command(shell)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); err != nil && !errors.As(err, &exitErr) {
+		return err
 	}
 	return nil
 }
@@ -358,6 +702,23 @@ func filterByAnyTag(entries []bookmarks.Bookmark, tags []string) []bookmarks.Boo
 	return filtered
 }
 
+func filterByAllTags(entries []bookmarks.Bookmark, tags []string) []bookmarks.Bookmark {
+	if len(tags) == 0 {
+		return entries
+	}
+	filtered := make([]bookmarks.Bookmark, 0, len(entries))
+	for _, e := range entries {
+		all := true
+		for _, t := range tags {
+			all = all && bookmarks.ContainsTag(e.Tags, t)
+		}
+		if all {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
 func cmdPath(storePath string, args []string) error {
 	if len(args) != 1 {
 		return errors.New("usage: bm path <name>")
@@ -368,7 +729,7 @@ func cmdPath(storePath string, args []string) error {
 	if err != nil {
 		return err
 	}
-	for _, entry := range entries {
+	for _, entry := range resolveRepoPaths(entries) {
 		if entry.Name == name {
 			fmt.Println(entry.Path)
 			return nil
@@ -377,6 +738,33 @@ func cmdPath(storePath string, args []string) error {
 	return fmt.Errorf("bookmark not found: %s", name)
 }
 
+func cmdCopy(storePath string, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: bm copy <name>")
+	}
+	name := args[0]
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	entries, err := bookmarks.Load(storePath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range resolveRepoPaths(entries) {
+		if entry.Name == name {
+			method, err := newClipboardWriter(cfg.Clipboard).copy(entry.Path)
+			if err != nil {
+				return fmt.Errorf("copy %s: %w", name, err)
+			}
+			fmt.Println(copyStatus(entry.Path, method))
+			return nil
+		}
+	}
+	return fmt.Errorf("bookmark not found: %s", name)
+}
+
 func cmdGo(storePath string, args []string) error {
 	if len(args) != 1 {
 		return errors.New("usage: bm go <name>")
@@ -388,7 +776,7 @@ func cmdGo(storePath string, args []string) error {
 		return err
 	}
 
-	for _, entry := range entries {
+	for _, entry := range resolveRepoPaths(entries) {
 		if entry.Name == name {
 			fmt.Printf("cd -- %s\n", shellQuote(entry.Path))
 			return nil
@@ -407,12 +795,12 @@ func formatGoCommand(name string) string {
 }
 
 func cmdUpdate(storePath string, args []string) error {
-	positionals, err := parseArgs(args, map[string]bool{"--name": true, "--tags": true})
+	positionals, err := parseArgs(args, map[string]bool{"--name": true, "--tags": true, "--desc": true, "--hook": true, "--repo-relative": false, "--absolute": false})
 	if err != nil {
 		return err
 	}
 	if len(positionals.args) != 1 {
-		return errors.New("usage: bm update <name> [--name <new>] [--tags a,b,c]")
+		return errors.New("usage: bm update <name> [--name <new>] [--tags a,b,c] [--desc text] [--hook cmd] [--repo-relative|--absolute]")
 	}
 	oldName := strings.TrimSpace(positionals.args[0])
 	if oldName == "" {
@@ -421,9 +809,19 @@ func cmdUpdate(storePath string, args []string) error {
 
 	newNameRaw, hasNewName := positionals.flags["--name"]
 	tagsRaw, hasTags := positionals.flags["--tags"]
+	desc, hasDesc := positionals.flags["--desc"]
+	hook, hasHook := positionals.flags["--hook"]
+	_, toRepo := positionals.flags["--repo-relative"]
+	_, toAbs := positionals.flags["--absolute"]
 
-	if !hasNewName && !hasTags {
-		return errors.New("nothing to update: provide --name and/or --tags")
+	if !hasNewName && !hasTags && !hasDesc && !hasHook && !toRepo && !toAbs {
+		return errors.New("nothing to update: provide --name, --tags, --desc, --hook, --repo-relative or --absolute")
+	}
+	if toRepo && toAbs {
+		return errors.New("--repo-relative and --absolute are mutually exclusive")
+	}
+	if strings.Contains(desc, "\n") {
+		return errors.New("description cannot contain newlines")
 	}
 
 	newName := strings.TrimSpace(newNameRaw)
@@ -461,12 +859,286 @@ func cmdUpdate(storePath string, args []string) error {
 		if hasTags {
 			entries[i].Tags = bookmarks.NormalizeTags(tagsRaw)
 		}
+		if hasDesc {
+			entries[i].Description = strings.TrimSpace(desc)
+		}
+		if hasHook {
+			entries[i].Hook = hook
+		}
+		if toRepo {
+			repo, repoPath, err := repoRelative(entries[i].Path)
+			if err != nil {
+				return err
+			}
+			entries[i].Repo, entries[i].RepoPath = repo, repoPath
+		}
+		if toAbs {
+			entries[i].Repo, entries[i].RepoPath = "", ""
+		}
+		entries[i].UpdatedAt = time.Now().UTC()
 	}
 
 	if !found {
 		return fmt.Errorf("bookmark not found: %s", oldName)
 	}
-	return bookmarks.Save(storePath, entries)
+	message := "update " + oldName
+	if hasNewName && newName != oldName {
+		message = "rename " + oldName + " to " + newName
+	}
+	return saveStore(storePath, entries, message)
+}
+
+func cmdNote(storePath string, args []string) error {
+	positionals, err := parseArgs(args, map[string]bool{"--print": false})
+	if err != nil {
+		return err
+	}
+	if len(positionals.args) != 1 {
+		return errors.New("usage: bm note <name> [--print]")
+	}
+	name := positionals.args[0]
+	_, printOnly := positionals.flags["--print"]
+
+	entries, err := bookmarks.Load(storePath)
+	if err != nil {
+		return err
+	}
+	index := -1
+	for i := range entries {
+		if entries[i].Name == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("bookmark not found: %s", name)
+	}
+
+	if printOnly {
+		if entries[index].Notes != "" {
+			fmt.Println(entries[index].Notes)
+		}
+		return nil
+	}
+
+	notes, err := editText(entries[index].Notes)
+	if err != nil {
+		return err
+	}
+	if notes == entries[index].Notes {
+		return nil
+	}
+	entries[index].Notes = notes
+	entries[index].UpdatedAt = time.Now().UTC()
+	return saveStore(storePath, entries, "note "+name)
+}
+
+// editText opens text in $VISUAL or $EDITOR (default vi) and returns the
+// edited content without trailing newlines.
+func editText(text string) (string, error) {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	tmp, err := os.CreateTemp("", "bm-note-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if text != "" {
+		text += "\n"
+	}
+	if _, err := tmp.WriteString(text); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], tmp.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s: %w", fields[0], err)
+	}
+
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(edited), "\r\n"), nil
+}
+
+// cmdHook prints the trusted on-enter hooks for a bookmark. The shell
+// integration evaluates the output after changing directory.
+func cmdHook(storePath string, args []string) error {
+	positionals, err := parseArgs(args, map[string]bool{"--shell": true})
+	if err != nil {
+		return err
+	}
+	if len(positionals.args) != 1 {
+		return errors.New("usage: bm hook [--shell name] <name>")
+	}
+	entry, hooks, err := loadHooks(storePath, positionals.args[0])
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	trustPath, err := bookmarks.DefaultTrustPath()
+	if err != nil {
+		return err
+	}
+	trusted, err := bookmarks.LoadTrust(trustPath)
+	if err != nil {
+		return err
+	}
+
+	// Hooks are sh commands, which fish evaluates too; other shells get a
+	// warning instead of a script they cannot run.
+	shell := positionals.flags["--shell"]
+	runs := shell == "" || shell == "bash" || shell == "zsh" || shell == "fish"
+	untrusted, skipped := 0, 0
+	for _, hook := range hooks {
+		switch {
+		case !trusted[bookmarks.HookDigest(entry.Path, hook.Script)]:
+			untrusted++
+		case !runs:
+			skipped++
+		default:
+			fmt.Println(hook.Script)
+		}
+	}
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "bm: %d hook(s) for %s not run: hooks only run in bash, zsh and fish, not %s\n",
+			skipped, entry.Name, shell)
+	}
+	if untrusted > 0 {
+		fmt.Fprintf(os.Stderr, "bm: %d untrusted hook(s) for %s not run; review with `bm trust %s`\n",
+			untrusted, entry.Name, entry.Name)
+	}
+	return nil
+}
+
+// cmdTrust adds or removes a bookmark's current hooks from the allow-list.
+// Hooks must be trusted again whenever their script or the bookmark path
+// changes.
+func cmdTrust(storePath string, args []string, trust bool) error {
+	verb := "trust"
+	if !trust {
+		verb = "untrust"
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: bm %s <name>", verb)
+	}
+	entry, hooks, err := loadHooks(storePath, args[0])
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		return fmt.Errorf("no hooks for bookmark: %s", entry.Name)
+	}
+
+	trustPath, err := bookmarks.DefaultTrustPath()
+	if err != nil {
+		return err
+	}
+	trusted, err := bookmarks.LoadTrust(trustPath)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		digest := bookmarks.HookDigest(entry.Path, hook.Script)
+		if trust {
+			trusted[digest] = true
+		} else {
+			delete(trusted, digest)
+		}
+		fmt.Printf("%sed %s hook for %s:\n", verb, hook.Source, entry.Name)
+		for _, line := range strings.Split(hook.Script, "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+	return bookmarks.SaveTrust(trustPath, trusted)
+}
+
+func loadHooks(storePath, name string) (bookmarks.Bookmark, []bookmarks.Hook, error) {
+	entries, err := bookmarks.Load(storePath)
+	if err != nil {
+		return bookmarks.Bookmark{}, nil, err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return bookmarks.Bookmark{}, nil, err
+	}
+	for _, entry := range entries {
+		if entry.Name == name {
+			return entry, bookmarks.HooksFor(entry, cfg.TagHooks), nil
+		}
+	}
+	return bookmarks.Bookmark{}, nil, fmt.Errorf("bookmark not found: %s", name)
+}
+
+// cmdRetag adds the tags from configured rules to existing bookmarks. Tags
+// are only added, never removed.
+func cmdRetag(storePath string, args []string) error {
+	positionals, err := parseArgs(args, map[string]bool{"--dry-run": false})
+	if err != nil {
+		return err
+	}
+	_, dryRun := positionals.flags["--dry-run"]
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if len(cfg.Rules) == 0 {
+		return errors.New("no tagging rules configured (add `rule = ...` lines to the config file)")
+	}
+	entries, err := bookmarks.Load(storePath)
+	if err != nil {
+		return err
+	}
+	only := map[string]bool{}
+	for _, name := range positionals.args {
+		only[name] = true
+	}
+
+	changed := 0
+	for i := range entries {
+		if len(only) > 0 && !only[entries[i].Name] {
+			continue
+		}
+		var added []string
+		for _, tag := range bookmarks.ApplyRules(cfg.Rules, entries[i].Path) {
+			if !bookmarks.ContainsTag(entries[i].Tags, tag) {
+				added = append(added, tag)
+			}
+		}
+		if len(added) == 0 {
+			continue
+		}
+		changed++
+		fmt.Printf("%s\t+%s\n", entries[i].Name, strings.Join(added, " +"))
+		entries[i].Tags = bookmarks.AddTags(entries[i].Tags, added)
+		entries[i].UpdatedAt = time.Now().UTC()
+	}
+
+	if dryRun || changed == 0 {
+		return nil
+	}
+	return saveStore(storePath, entries, fmt.Sprintf("retag %d bookmark(s)", changed))
 }
 
 func cmdRemove(storePath string, args []string) error {
@@ -504,36 +1176,128 @@ func cmdRemove(storePath string, args []string) error {
 		return fmt.Errorf("bookmark not found: %s", name)
 	}
 
-	return bookmarks.Save(storePath, result)
+	return saveStore(storePath, result, "rm "+name)
+}
+
+// themeFlag returns the theme named by --theme, falling back to the config.
+func themeFlag(flags map[string]string, cfg bookmarks.Config) string {
+	if v, ok := flags["--theme"]; ok {
+		return strings.ToLower(strings.TrimSpace(v))
+	}
+	return cfg.Theme
+}
+
+// loadConfig reads the user config file.
+func loadConfig() (bookmarks.Config, error) {
+	path, err := bookmarks.DefaultConfigPath()
+	if err != nil {
+		return bookmarks.Config{}, err
+	}
+	return bookmarks.LoadConfig(path)
+}
+
+// saveStore writes the store and, for git-backed stores, commits the change
+// with the given message.
+func saveStore(storePath string, entries []bookmarks.Bookmark, message string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := bookmarks.Save(storePath, entries); err != nil {
+		return err
+	}
+	if !cfg.Git {
+		return nil
+	}
+	return bookmarks.GitCommit(storePath, message)
+}
+
+func cmdSync(storePath string, args []string) error {
+	positionals, err := parseArgs(args, map[string]bool{"--remote": true, "--branch": true})
+	if err != nil {
+		return err
+	}
+	if len(positionals.args) != 0 {
+		return errors.New("usage: bm sync [--remote <name>] [--branch <name>]")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	remote := cfg.GitRemote
+	if v, ok := positionals.flags["--remote"]; ok {
+		remote = v
+	}
+	branch := cfg.GitBranch
+	if v, ok := positionals.flags["--branch"]; ok {
+		branch = v
+	}
+
+	result, err := bookmarks.Sync(storePath, remote, branch)
+	if err != nil {
+		return err
+	}
+	for _, c := range result.Conflicts {
+		fmt.Fprintf(os.Stderr, "conflict: %s: %s %s (kept local version)\n", c.Name, c.Field, c.Reason)
+	}
+	switch {
+	case result.Pulled && result.Pushed:
+		fmt.Println("synced: pulled and pushed")
+	case result.Pulled:
+		fmt.Println("synced: pulled")
+	case result.Pushed:
+		fmt.Println("synced: pushed")
+	default:
+		fmt.Println("already up to date")
+	}
+	return nil
 }
 
 func cmdShell(args []string) error {
 	if len(args) == 0 || args[0] != "init" {
-		return errors.New("usage: bm shell init [bash|zsh|fish]")
+		return errors.New("usage: bm shell init [bash|zsh|fish|pwsh|nu|elvish|xonsh] [--record] [--cmd prefix] [--no-wrap-bm] [--bind key]")
 	}
 	return cmdInit(args[1:])
 }
 
 func cmdInit(args []string) error {
-	if len(args) > 1 {
-		return errors.New("usage: bm init [bash|zsh|fish]")
+	positionals, err := parseArgs(args, map[string]bool{"--record": false, "--cmd": true, "--no-wrap-bm": false, "--bind": true})
+	if err != nil {
+		return err
+	}
+	if len(positionals.args) > 1 {
+		return errors.New("usage: bm init [bash|zsh|fish|pwsh|nu|elvish|xonsh] [--record] [--cmd prefix] [--no-wrap-bm] [--bind key]")
+	}
+	_, record := positionals.flags["--record"]
+	opts, err := parseShellInitOptions(positionals.flags)
+	if err != nil {
+		return err
 	}
 
-	shellName, err := resolveShellName(args)
+	shellName, err := resolveShellName(positionals.args)
 	if err != nil {
 		return err
 	}
 
-	switch shellName {
-	case "bash", "zsh":
-		fmt.Print(shellInitScriptSh())
-		return nil
-	case "fish":
-		fmt.Print(shellInitScriptFish())
-		return nil
-	default:
-		return fmt.Errorf("unsupported shell: %s (expected bash, zsh, or fish)", shellName)
+	script, err := shellInitScript(shellName, opts)
+	if err != nil {
+		return err
 	}
+	fmt.Print(script)
+	if record {
+		fmt.Print("\n" + shellRecordScript(shellName))
+	}
+	return nil
+}
+
+const supportedShells = "bash, zsh, fish, pwsh, nu, elvish or xonsh"
+
+// shellAliases maps other names of the supported shells, as given on the
+// command line or found in $SHELL, to the names bm init uses.
+var shellAliases = map[string]string{
+	"powershell": "pwsh",
+	"nushell":    "nu",
 }
 
 func resolveShellName(args []string) (string, error) {
@@ -542,96 +1306,112 @@ func resolveShellName(args []string) (string, error) {
 		if name == "" {
 			return "", errors.New("shell cannot be empty")
 		}
+		if alias, ok := shellAliases[name]; ok {
+			name = alias
+		}
 		return name, nil
 	}
 
 	shellEnv := strings.TrimSpace(os.Getenv("SHELL"))
 	if shellEnv == "" {
-		return "", errors.New("could not detect shell; pass one of: bash, zsh, fish")
+		return "", fmt.Errorf("could not detect shell; pass one of: %s", supportedShells)
 	}
 
-	base := strings.ToLower(filepath.Base(shellEnv))
-	if strings.HasPrefix(base, "bash") {
-		return "bash", nil
-	}
-	if strings.HasPrefix(base, "zsh") {
-		return "zsh", nil
-	}
-	if strings.HasPrefix(base, "fish") {
-		return "fish", nil
+	base := strings.TrimSuffix(strings.ToLower(filepath.Base(shellEnv)), ".exe")
+	for _, name := range []string{"bash", "zsh", "fish", "pwsh", "powershell", "nushell", "nu", "elvish", "xonsh"} {
+		if strings.HasPrefix(base, name) {
+			if alias, ok := shellAliases[name]; ok {
+				return alias, nil
+			}
+			return name, nil
+		}
 	}
 
-	return "", fmt.Errorf("unsupported shell from SHELL=%q; pass one of: bash, zsh, fish", shellEnv)
+	return "", fmt.Errorf("unsupported shell from SHELL=%q; pass one of: %s", shellEnv, supportedShells)
 }
 
-func shellInitScriptSh() string {
-	return strings.TrimLeft(`bm() {
-  if [ "$1" = "go" ]; then
-    shift
-    local cmd
-    cmd="$(command bm go "$@")" || return
-    eval "$cmd"
-    return
-  fi
-  if [ "$1" = "find" ] || [ "$1" = "table" ]; then
-    local cmd
-    cmd="$(command bm "$@")" || return
-    [ -n "$cmd" ] && eval "$cmd"
-    return
-  fi
-  command bm "$@"
+// shellRecordScript returns a hook that runs `bm record` whenever the shell
+// changes directory.
+func shellRecordScript(shellName string) string {
+	switch shellName {
+	case "zsh":
+		return strings.TrimLeft(`
+__bm_record() {
+  command bm record "$PWD" >/dev/null 2>&1
 }
-
-bmcd() {
-  local dir
-  dir="$(command bm find "$@")" || return
-  [ -n "$dir" ] && cd "$dir"
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd __bm_record
+`, "\n")
+	case "fish":
+		return strings.TrimLeft(`
+function __bm_record --on-variable PWD
+  command bm record "$PWD" >/dev/null 2>&1
+end
+`, "\n")
+	case "pwsh":
+		// PowerShell has no chpwd hook either; wrap the prompt function,
+		// restoring $? and $LASTEXITCODE for the prompt it wraps.
+		return strings.TrimLeft(`
+if (-not $global:__bm_prompt) { $global:__bm_prompt = $function:prompt }
+function global:prompt {
+  $ok = $global:?
+  $code = $global:LASTEXITCODE
+  $loc = Get-Location
+  if ($loc.Provider.Name -eq 'FileSystem' -and $loc.ProviderPath -ne $global:__bm_last_pwd) {
+    $global:__bm_last_pwd = $loc.ProviderPath
+    & (__bm_exe) record $loc.ProviderPath *> $null
+  }
+  $global:LASTEXITCODE = $code
+  if (-not $ok) { Write-Error '' -ErrorAction Ignore }
+  & $global:__bm_prompt
 }
-
-bmgo() {
-  if [ "$#" -ne 1 ]; then
-    printf 'usage: bmgo <name>\n' >&2
-    return 1
-  fi
-  bm go "$1"
+`, "\n")
+	case "nu":
+		return strings.TrimLeft(`
+$env.config = (
+  $env.config?
+  | default {}
+  | upsert hooks { default {} }
+  | upsert hooks.env_change { default {} }
+  | upsert hooks.env_change.PWD { default [] }
+)
+let __bm_hooked = ($env.config.hooks.env_change.PWD | any {|hook| try { $hook | get __bm_record } catch { false } })
+if not $__bm_hooked {
+  $env.config.hooks.env_change.PWD = ($env.config.hooks.env_change.PWD | append {
+    __bm_record: true,
+    code: {|_, dir| ^bm record $dir | complete | ignore }
+  })
 }
 `, "\n")
+	case "elvish":
+		return strings.TrimLeft(`
+set after-chdir = [$@after-chdir {|_| try { e:bm record $pwd >/dev/null 2>/dev/null } catch { } }]
+`, "\n")
+	case "xonsh":
+		return strings.TrimLeft(`
+@events.on_chdir
+def __bm_record(olddir, newdir, **kwargs):
+    _bm_run("record", newdir, capture=True)
+`, "\n")
+	default:
+		// bash has no chpwd hook; run last from PROMPT_COMMAND, keeping $?
+		// for the other prompt commands, and skip prompts that did not
+		// change directory.
+		return strings.TrimLeft(`
+__bm_record() {
+  local s=$?
+  if [ "$PWD" != "${__bm_last_pwd-}" ]; then
+    __bm_last_pwd="$PWD"
+    command bm record "$PWD" >/dev/null 2>&1
+  fi
+  return $s
 }
-
-func shellInitScriptFish() string {
-	return strings.TrimLeft(`function bm
-  if test (count $argv) -gt 0
-    if test "$argv[1]" = "go"
-      set -e argv[1]
-      set -l cmd (command bm go $argv)
-      or return
-      eval $cmd
-      return
-    end
-    if test "$argv[1]" = "find"; or test "$argv[1]" = "table"
-      set -l cmd (command bm $argv)
-      or return
-      test -n "$cmd"; and eval $cmd
-      return
-    end
-  end
-  command bm $argv
-end
-
-function bmcd
-  set -l dir (command bm find $argv)
-  or return
-  test -n "$dir"; and cd "$dir"
-end
-
-function bmgo
-  if test (count $argv) -ne 1
-    echo "usage: bmgo <name>" >&2
-    return 1
-  end
-  bm go $argv[1]
-end
+case ";${PROMPT_COMMAND-};" in
+  *";__bm_record;"*) ;;
+  *) PROMPT_COMMAND="${PROMPT_COMMAND:+$PROMPT_COMMAND;}__bm_record" ;;
+esac
 `, "\n")
+	}
 }
 
 type parsedArgs struct {
@@ -696,17 +1476,31 @@ func usage() string {
 	return strings.TrimSpace(`usage:
   bm --version
   bm [--store <path>] <command>
-  bm add [name] [path] [--tags a,b,c] [-f|--force]
-  bm ls [--json] [--tag x]
+  bm add [name] [path] [--tags a,b,c] [--desc text] [--hook cmd] [--repo-relative] [-f|--force]
+  bm ls [--json] [--long] [--tag x]
   bm tags [--json]
-  bm find [--tag x] [--tags a,b,c]
-  bm table [--tag x] [--tags a,b,c]
+  bm find [--tag x] [--tags a,b,c] [--query q] [--no-tui] [--fzf] [--multi] [--paths] [--print path|name|cd|go] [--spawn-shell] [-0|--print0] [--theme name]
+  bm tui [--paths] [--print path|name|cd|go] [--spawn-shell] [--theme name]
+  bm table [--tag x] [--tags a,b,c] [--git] [--columns a,b,c] [--multi] [--paths] [--print path|name|cd|go] [--spawn-shell] [-0|--print0] [--theme name]
   bm path <name>
+  bm copy <name>
   bm go <name>
-  bm init [bash|zsh|fish]
-  bm update <name> [--name <new>] [--tags a,b,c]
+  bm init [bash|zsh|fish|pwsh|nu|elvish|xonsh] [--record] [--cmd prefix] [--no-wrap-bm] [--bind key]
+  bm update <name> [--name <new>] [--tags a,b,c] [--desc text] [--hook cmd] [--repo-relative|--absolute]
+  bm note <name> [--print]
+  bm exec <name|--where query> [--parallel N] [--json] -- <cmd...>
+  bm status [--tag x] [--json] [--timeout 2s]
+  bm scan <root> [--depth N] [--yes] [--dry-run] [--theme name]
+  bm retag [name...] [--dry-run]
+  bm suggest [--history file] [--limit N] [--json] [--theme name]
+  bm record <dir>
+  bm hook [--shell name] <name>
+  bm trust <name>
+  bm untrust <name>
   bm rm <name> [-f|--force]
-  bm shell init [bash|zsh|fish]   (compat)
+  bm sync [--remote <name>] [--branch <name>]
+  bm merge <base> <ours> <theirs> [--report <file>]
+  bm shell init [bash|zsh|fish|pwsh|nu|elvish|xonsh]   (compat)
 
 global flags:
   --store <path>   override default store path
@@ -763,3 +1557,51 @@ func resolveStorePath(path string) (string, error) {
 	}
 	return filepath.Clean(filepath.Join(cwd, trimmed)), nil
 }
+
+// cmdMerge merges three versions of a store file into ours. It follows the
+// git merge driver protocol (`bm merge %O %A %B`): unresolved conflicts keep
+// the ours value, are reported as JSON, and make the command fail.
+func cmdMerge(args []string) error {
+	positionals, err := parseArgs(args, map[string]bool{"--report": true})
+	if err != nil {
+		return err
+	}
+	if len(positionals.args) != 3 {
+		return errors.New("usage: bm merge <base> <ours> <theirs> [--report <file>]")
+	}
+	basePath, oursPath, theirsPath := positionals.args[0], positionals.args[1], positionals.args[2]
+
+	base, err := bookmarks.Load(basePath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", basePath, err)
+	}
+	ours, err := bookmarks.Load(oursPath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", oursPath, err)
+	}
+	theirs, err := bookmarks.Load(theirsPath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", theirsPath, err)
+	}
+
+	merged, conflicts := bookmarks.Merge(base, ours, theirs)
+	if err := bookmarks.Save(oursPath, merged); err != nil {
+		return err
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	encoded, err := json.MarshalIndent(conflicts, "", "  ")
+	if err != nil {
+		return err
+	}
+	if reportPath, ok := positionals.flags["--report"]; ok {
+		if err := os.WriteFile(reportPath, append(encoded, '\n'), 0o644); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintln(os.Stderr, string(encoded))
+	}
+	return fmt.Errorf("%d unresolved conflict(s); kept ours", len(conflicts))
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -14,6 +15,35 @@ import (
 	"github.com/navio/bookmarks/internal/bookmarks"
 )
 
+// TestMain points config and state lookups at a scratch directory so the
+// user's own bm setup never leaks into tests.
+func TestMain(m *testing.M) {
+	if os.Getenv("BM_TEST_RUN_MAIN") == "1" {
+		// The shell integration tests run this binary as bm.
+		main()
+		os.Exit(0)
+	}
+	home, err := os.MkdirTemp("", "bm-test-home-")
+	if err != nil {
+		panic(err)
+	}
+	_ = os.Unsetenv("BM_CONFIG")
+	_ = os.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	_ = os.Setenv("XDG_STATE_HOME", filepath.Join(home, "state"))
+	code := m.Run()
+	_ = os.RemoveAll(home)
+	os.Exit(code)
+}
+
+func writeConfig(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("BM_CONFIG", path)
+}
+
 func captureStdout(t *testing.T, fn func() error) (string, error) {
 	t.Helper()
 	old := os.Stdout
@@ -36,6 +66,24 @@ func captureStdout(t *testing.T, fn func() error) (string, error) {
 	return buf.String(), fnErr
 }
 
+// withStdin runs fn with input readable on os.Stdin.
+func withStdin(t *testing.T, input string, fn func() error) error {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatalf("write stdin: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open stdin: %v", err)
+	}
+	defer f.Close()
+	old := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = old }()
+	return fn()
+}
+
 func TestCmdAdd_NoNameUsesCurrentDirBase(t *testing.T) {
 	root := t.TempDir()
 	projDir := filepath.Join(root, "myproj")
@@ -246,9 +294,9 @@ func TestCmdInit_AutodetectShell(t *testing.T) {
 }
 
 func TestCmdInit_UnsupportedShell(t *testing.T) {
-	err := cmdInit([]string{"pwsh"})
-	if err == nil {
-		t.Fatalf("expected unsupported shell error")
+	err := cmdInit([]string{"tcsh"})
+	if err == nil || !strings.Contains(err.Error(), "expected bash, zsh, fish, pwsh, nu, elvish or xonsh") {
+		t.Fatalf("expected unsupported shell error, got %v", err)
 	}
 }
 
@@ -304,3 +352,265 @@ func TestFormatGoCommand(t *testing.T) {
 		t.Fatalf("formatGoCommand()=%q, want %q", got, want)
 	}
 }
+
+// requireGit skips the test without git and isolates git from the user's
+// configuration.
+func requireGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "bm test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "bm@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "bm test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "bm@example.com")
+}
+
+// mustGit runs git with args and returns its output.
+func mustGit(t *testing.T, args ...string) string {
+	t.Helper()
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, stderr.String())
+	}
+	return string(out)
+}
+
+func TestSaveStore_GitBackedCommitsEachChange(t *testing.T) {
+	requireGit(t)
+	writeConfig(t, "git = true\n")
+
+	root := t.TempDir()
+	mustGit(t, "-C", root, "init", "-q", "-b", "main")
+	storePath := filepath.Join(root, "bm.tsv")
+	if err := cmdAdd(storePath, []string{"proj", root}); err != nil {
+		t.Fatalf("cmdAdd() error = %v", err)
+	}
+	if err := cmdUpdate(storePath, []string{"proj", "--name", "proj2"}); err != nil {
+		t.Fatalf("cmdUpdate() error = %v", err)
+	}
+	if err := cmdRemove(storePath, []string{"proj2"}); err != nil {
+		t.Fatalf("cmdRemove() error = %v", err)
+	}
+
+	out := mustGit(t, "-C", root, "log", "--format=%s")
+	want := "rm proj2\nrename proj to proj2\nadd proj\n"
+	if out != want {
+		t.Fatalf("git log=%q, want %q", out, want)
+	}
+}
+
+func TestCmdMerge_WritesOursAndReportsConflicts(t *testing.T) {
+	root := t.TempDir()
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	write := func(name string, entries []bookmarks.Bookmark) string {
+		p := filepath.Join(root, name)
+		if err := bookmarks.Save(p, entries); err != nil {
+			t.Fatalf("Save(%s) error = %v", name, err)
+		}
+		return p
+	}
+	base := write("base", []bookmarks.Bookmark{
+		{Name: "a", Path: "/tmp/a", CreatedAt: created},
+		{Name: "b", Path: "/tmp/b", CreatedAt: created},
+	})
+	ours := write("ours", []bookmarks.Bookmark{
+		{Name: "b", Path: "/tmp/b", Tags: []string{"work"}, CreatedAt: created},
+		{Name: "a", Path: "/tmp/a-ours", CreatedAt: created},
+	})
+	theirs := write("theirs", []bookmarks.Bookmark{
+		{Name: "a", Path: "/tmp/a-theirs", CreatedAt: created},
+		{Name: "b", Path: "/tmp/b", Tags: []string{"go"}, CreatedAt: created},
+		{Name: "c", Path: "/tmp/c", CreatedAt: created},
+	})
+	report := filepath.Join(root, "report.json")
+
+	err := cmdMerge([]string{base, ours, theirs, "--report", report})
+	if err == nil {
+		t.Fatalf("expected unresolved conflict error")
+	}
+
+	merged, err := bookmarks.Load(ours)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(merged) != 3 || merged[0].Name != "b" || merged[1].Path != "/tmp/a-ours" || merged[2].Name != "c" {
+		t.Fatalf("merged = %#v", merged)
+	}
+	if got := strings.Join(merged[0].Tags, ","); got != "work,go" {
+		t.Fatalf("b tags = %q, want union", got)
+	}
+
+	raw, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatalf("ReadFile(report) error = %v", err)
+	}
+	var conflicts []bookmarks.Conflict
+	if err := json.Unmarshal(raw, &conflicts); err != nil {
+		t.Fatalf("json unmarshal error = %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Name != "a" || conflicts[0].Field != "path" || conflicts[0].Theirs != "/tmp/a-theirs" {
+		t.Fatalf("conflicts = %#v", conflicts)
+	}
+}
+
+func TestCmdNote_EditsNotesInEditor(t *testing.T) {
+	root := t.TempDir()
+	storePath := filepath.Join(root, "bm.tsv")
+	if err := cmdAdd(storePath, []string{"proj", root, "--desc", "prod hotfix checkout"}); err != nil {
+		t.Fatalf("cmdAdd() error = %v", err)
+	}
+
+	editor := filepath.Join(root, "editor.sh")
+	script := "#!/bin/sh\nprintf 'delete after release\\nask ops first\\n' > \"$1\"\n"
+	if err := os.WriteFile(editor, []byte(script), 0o755); err != nil {
+		t.Fatalf("write editor: %v", err)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+
+	if err := cmdNote(storePath, []string{"proj"}); err != nil {
+		t.Fatalf("cmdNote() error = %v", err)
+	}
+
+	entries, err := bookmarks.Load(storePath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if entries[0].Description != "prod hotfix checkout" {
+		t.Fatalf("Description=%q", entries[0].Description)
+	}
+	if entries[0].Notes != "delete after release\nask ops first" {
+		t.Fatalf("Notes=%q", entries[0].Notes)
+	}
+
+	out, err := captureStdout(t, func() error {
+		return cmdNote(storePath, []string{"proj", "--print"})
+	})
+	if err != nil {
+		t.Fatalf("cmdNote(--print) error = %v", err)
+	}
+	if out != "delete after release\nask ops first\n" {
+		t.Fatalf("stdout=%q", out)
+	}
+}
+
+func TestCmdHook_RequiresTrust(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	writeConfig(t, "hook.tag.python = source .venv/bin/activate\n")
+	root := t.TempDir()
+	storePath := filepath.Join(root, "bm.tsv")
+	if err := cmdAdd(storePath, []string{"svc", root, "--tags", "python", "--hook", "nvm use"}); err != nil {
+		t.Fatalf("cmdAdd() error = %v", err)
+	}
+
+	out, err := captureStdout(t, func() error {
+		return cmdHook(storePath, []string{"svc"})
+	})
+	if err != nil {
+		t.Fatalf("cmdHook() error = %v", err)
+	}
+	if out != "" {
+		t.Fatalf("untrusted hooks printed: %q", out)
+	}
+
+	if _, err := captureStdout(t, func() error {
+		return cmdTrust(storePath, []string{"svc"}, true)
+	}); err != nil {
+		t.Fatalf("cmdTrust() error = %v", err)
+	}
+	out, err = captureStdout(t, func() error {
+		return cmdHook(storePath, []string{"svc"})
+	})
+	if err != nil {
+		t.Fatalf("cmdHook() error = %v", err)
+	}
+	if want := "nvm use\nsource .venv/bin/activate\n"; out != want {
+		t.Fatalf("stdout=%q, want %q", out, want)
+	}
+	// Shells that cannot run sh get no script, only a warning.
+	for _, shell := range []string{"pwsh", "nu", "elvish", "xonsh"} {
+		out, err = captureStdout(t, func() error {
+			return cmdHook(storePath, []string{"--shell", shell, "svc"})
+		})
+		if err != nil || out != "" {
+			t.Fatalf("cmdHook(--shell %s) = %q, %v", shell, out, err)
+		}
+	}
+	out, _ = captureStdout(t, func() error {
+		return cmdHook(storePath, []string{"--shell", "fish", "svc"})
+	})
+	if want := "nvm use\nsource .venv/bin/activate\n"; out != want {
+		t.Fatalf("cmdHook(--shell fish) stdout=%q, want %q", out, want)
+	}
+
+	// Changing the script revokes trust for it.
+	if err := cmdUpdate(storePath, []string{"svc", "--hook", "rm -rf ~"}); err != nil {
+		t.Fatalf("cmdUpdate() error = %v", err)
+	}
+	out, err = captureStdout(t, func() error {
+		return cmdHook(storePath, []string{"svc"})
+	})
+	if err != nil {
+		t.Fatalf("cmdHook() error = %v", err)
+	}
+	if want := "source .venv/bin/activate\n"; out != want {
+		t.Fatalf("stdout=%q, want %q", out, want)
+	}
+}
+
+func TestCmdRetag_AppliesRules(t *testing.T) {
+	root := t.TempDir()
+	acme := filepath.Join(root, "acme")
+	if err := os.MkdirAll(acme, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(acme, "go.mod"), nil, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	storePath := filepath.Join(root, "bm.tsv")
+	if err := cmdAdd(storePath, []string{"acme", acme, "--tags", "work"}); err != nil {
+		t.Fatalf("cmdAdd() error = %v", err)
+	}
+	if err := cmdAdd(storePath, []string{"other", root}); err != nil {
+		t.Fatalf("cmdAdd() error = %v", err)
+	}
+
+	writeConfig(t, "rule = under "+acme+" -> client/acme\nrule = has go.mod -> lang/go\n")
+
+	out, err := captureStdout(t, func() error {
+		return cmdRetag(storePath, []string{"--dry-run"})
+	})
+	if err != nil {
+		t.Fatalf("cmdRetag(--dry-run) error = %v", err)
+	}
+	if want := "acme\t+client/acme +lang/go\n"; out != want {
+		t.Fatalf("stdout=%q, want %q", out, want)
+	}
+	entries, _ := bookmarks.Load(storePath)
+	if strings.Join(entries[0].Tags, ",") != "work" {
+		t.Fatalf("dry run changed tags: %v", entries[0].Tags)
+	}
+
+	if _, err := captureStdout(t, func() error { return cmdRetag(storePath, nil) }); err != nil {
+		t.Fatalf("cmdRetag() error = %v", err)
+	}
+	entries, _ = bookmarks.Load(storePath)
+	if got := strings.Join(entries[0].Tags, ","); got != "work,client/acme,lang/go" {
+		t.Fatalf("tags=%q", got)
+	}
+
+	// New bookmarks get rule tags on add.
+	if err := cmdAdd(storePath, []string{"acme2", acme}); err != nil {
+		t.Fatalf("cmdAdd() error = %v", err)
+	}
+	entries, _ = bookmarks.Load(storePath)
+	if got := strings.Join(entries[2].Tags, ","); got != "client/acme,lang/go" {
+		t.Fatalf("added tags=%q", got)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+	"github.com/navio/bookmarks/internal/gitinfo"
+)
+
+const (
+	previewReadmeLines = 12
+	previewListEntries = 30
+)
+
+// readmeNames are tried in order when looking for a README to preview.
+var readmeNames = []string{"README.md", "README", "README.txt", "README.rst", "readme.md"}
+
+// previewData is what the find view shows about a directory. It is loaded off
+// the UI goroutine and cached by path.
+type previewData struct {
+	listing []string
+	more    int
+	readme  string
+	lines   []string
+	git     string
+	err     error
+}
+
+// previewMsg delivers the preview of path.
+type previewMsg struct {
+	path string
+	data previewData
+}
+
+// loadPreview reads the directory listing, README head and git status of
+// path.
+func loadPreview(path string) tea.Cmd {
+	return func() tea.Msg {
+		return previewMsg{path: path, data: readPreview(path)}
+	}
+}
+
+func readPreview(path string) previewData {
+	var p previewData
+	dirEntries, err := os.ReadDir(path)
+	if err != nil {
+		// The path is already shown above the error.
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		p.err = err
+		return p
+	}
+	sort.SliceStable(dirEntries, func(i, j int) bool {
+		return dirEntries[i].IsDir() && !dirEntries[j].IsDir()
+	})
+	for i, e := range dirEntries {
+		if i == previewListEntries {
+			p.more = len(dirEntries) - i
+			break
+		}
+		name := printable(e.Name())
+		if e.IsDir() {
+			name += "/"
+		}
+		p.listing = append(p.listing, name)
+	}
+
+	for _, name := range readmeNames {
+		lines, err := headLines(filepath.Join(path, name), previewReadmeLines)
+		if err == nil {
+			p.readme, p.lines = name, lines
+			break
+		}
+	}
+
+	r := gitCache.InspectAll(context.Background(), []string{path}, gitTimeout, 1)[0]
+	if !errors.Is(r.Err, gitinfo.ErrNotRepo) {
+		p.git = formatGitCell(r)
+	}
+	return p
+}
+
+func headLines(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for len(lines) < n && scanner.Scan() {
+		lines = append(lines, printable(scanner.Text()))
+	}
+	return lines, scanner.Err()
+}
+
+// printable strips escape sequences and control characters, other than tabs,
+// so file contents cannot restyle or move around the terminal.
+func printable(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r != '\t' && unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, ansi.Strip(s))
+}
+
+// renderPreview draws the preview pane for entry in a box of the given outer
+// size. data is nil while the preview is still loading.
+func renderPreview(entry bookmarks.Bookmark, data *previewData, width, height int) string {
+	inner := max(1, width-ui.previewBorder.GetHorizontalFrameSize())
+	var lines []string
+	add := func(s string) { lines = append(lines, s) }
+
+	add(ui.heading.Render(entry.Name))
+	add(entry.Path)
+	if len(entry.Tags) > 0 {
+		add(ui.dim.Render("tags: ") + strings.Join(entry.Tags, ", "))
+	}
+	if entry.Description != "" {
+		add(entry.Description)
+	}
+
+	switch {
+	case data == nil:
+		add("")
+		add(ui.dim.Render("loading…"))
+	case data.err != nil:
+		add("")
+		add(ui.dim.Render(data.err.Error()))
+	default:
+		if data.git != "" {
+			add(ui.dim.Render("git: ") + data.git)
+		}
+		if data.readme != "" {
+			add("")
+			add(ui.heading.Render(data.readme))
+			lines = append(lines, data.lines...)
+		}
+		add("")
+		lines = append(lines, data.listing...)
+		if data.more > 0 {
+			add(ui.dim.Render("… " + strconv.Itoa(data.more) + " more"))
+		}
+	}
+
+	innerHeight := max(1, height-ui.previewBorder.GetVerticalFrameSize())
+	if len(lines) > innerHeight {
+		lines = lines[:innerHeight]
+	}
+	for i, l := range lines {
+		lines[i] = ansi.Truncate(strings.ReplaceAll(l, "\t", "    "), inner, "…")
+	}
+	return ui.previewBorder.
+		Width(max(1, width-ui.previewBorder.GetHorizontalBorderSize())).
+		Height(innerHeight).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+// hasTerminal reports whether the TUIs can run: they read keys from stdin and
+// draw on stderr, so both must be terminals.
+func hasTerminal() bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return term.IsTerminal(os.Stdin.Fd()) && term.IsTerminal(os.Stderr.Fd())
+}
+
+// promptSelect is the picker used without a terminal: it asks for an optional
+// substring filter (unless filter is false), prints the matching bookmarks as a
+// numbered list on out and reads the chosen number from in. With multi,
+// several numbers separated by spaces or commas may be given. An empty answer
+// cancels and returns no names.
+func promptSelect(entries []bookmarks.Bookmark, in io.Reader, out io.Writer, filter, multi bool) ([]string, error) {
+	reader := bufio.NewReader(in)
+	if filter {
+		fmt.Fprint(out, "filter (empty for all): ")
+		text, err := readAnswer(reader)
+		if err != nil {
+			return nil, err
+		}
+		entries = filterBySubstring(entries, text)
+		if len(entries) == 0 {
+			return nil, fmt.Errorf("no bookmarks match %q", text)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no bookmarks")
+	}
+
+	width := len(strconv.Itoa(len(entries)))
+	for i, e := range entries {
+		line := fmt.Sprintf("%*d) %s\t%s", width, i+1, e.Name, e.Path)
+		if len(e.Tags) > 0 {
+			line += "\t[" + strings.Join(e.Tags, ",") + "]"
+		}
+		fmt.Fprintln(out, line)
+	}
+	if multi {
+		fmt.Fprintf(out, "select one or more [1-%d]: ", len(entries))
+	} else {
+		fmt.Fprintf(out, "select [1-%d]: ", len(entries))
+	}
+	answer, err := readAnswer(reader)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.FieldsFunc(answer, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) > 1 && !multi {
+		return nil, fmt.Errorf("invalid selection: %q", answer)
+	}
+	var names []string
+	seen := map[int]bool{}
+	for _, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 || n > len(entries) {
+			return nil, fmt.Errorf("invalid selection: %q", answer)
+		}
+		if !seen[n] {
+			seen[n] = true
+			names = append(names, entries[n-1].Name)
+		}
+	}
+	return names, nil
+}
+
+func readAnswer(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", errors.New("no input; pass --query to select without prompting")
+	}
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// filterBySubstring keeps entries whose name, path, tags, description or notes
+// contain text, ignoring case.
+func filterBySubstring(entries []bookmarks.Bookmark, text string) []bookmarks.Bookmark {
+	text = strings.ToLower(text)
+	if text == "" {
+		return entries
+	}
+	var out []bookmarks.Bookmark
+	for _, e := range entries {
+		if strings.Contains(strings.ToLower(bookmarkItem{b: e}.FilterValue()), text) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// matchQuery returns the bookmarks matching a --query. A bookmark whose name
+// equals the query wins over other matches.
+func matchQuery(entries []bookmarks.Bookmark, query string) ([]bookmarks.Bookmark, error) {
+	for _, e := range entries {
+		if e.Name == query {
+			return []bookmarks.Bookmark{e}, nil
+		}
+	}
+	q, err := bookmarks.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return q.Filter(entries), nil
+}
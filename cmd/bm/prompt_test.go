@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+func promptEntries() []bookmarks.Bookmark {
+	return []bookmarks.Bookmark{
+		{Name: "api", Path: "/src/api", Tags: []string{"work"}},
+		{Name: "api-old", Path: "/src/api-old"},
+		{Name: "web", Path: "/src/web", Tags: []string{"work"}},
+	}
+}
+
+func TestPromptSelect(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		filter  bool
+		multi   bool
+		want    string
+		wantErr string
+	}{
+		{name: "all", input: "\n3\n", filter: true, want: "web"},
+		{name: "filtered", input: "API\n2\n", filter: true, want: "api-old"},
+		{name: "no filter prompt", input: "1\n", want: "api"},
+		{name: "cancel", input: "\n\n", filter: true, want: ""},
+		{name: "out of range", input: "\n9\n", filter: true, wantErr: "invalid selection"},
+		{name: "no match", input: "zzz\n", filter: true, wantErr: "no bookmarks match"},
+		{name: "no input", input: "", filter: true, wantErr: "no input"},
+		{name: "last line without newline", input: "web\n1", filter: true, want: "web"},
+		{name: "several without multi", input: "1 2\n", wantErr: "invalid selection"},
+		{name: "multi", input: "3, 1 3\n", multi: true, want: "web api"},
+		{name: "multi out of range", input: "1 4\n", multi: true, wantErr: "invalid selection"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := promptSelect(promptEntries(), strings.NewReader(tt.input), &out, tt.filter, tt.multi)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("promptSelect() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("promptSelect() error = %v", err)
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Fatalf("promptSelect() = %q, want %q\n%s", got, tt.want, out.String())
+			}
+		})
+	}
+}
+
+func TestPromptSelect_PrintsNumberedList(t *testing.T) {
+	var out bytes.Buffer
+	if _, err := promptSelect(promptEntries(), strings.NewReader("work\n\n"), &out, true, false); err != nil {
+		t.Fatalf("promptSelect() error = %v", err)
+	}
+	want := "filter (empty for all): 1) api\t/src/api\t[work]\n2) web\t/src/web\t[work]\nselect [1-2]: "
+	if out.String() != want {
+		t.Fatalf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestCmdFind_QuerySelectsSingleMatch(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "bm.tsv")
+	if err := bookmarks.Save(storePath, promptEntries()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	tests := []struct {
+		query   string
+		want    string
+		wantErr string
+	}{
+		{query: "api", want: "bm go 'api'\n"},
+		{query: "web", want: "bm go 'web'\n"},
+		{query: "path:old", want: "bm go 'api-old'\n"},
+		{query: "nothing", wantErr: "no bookmarks match"},
+	}
+	for _, tt := range tests {
+		out, err := captureStdout(t, func() error {
+			return cmdFind(storePath, []string{"--query", tt.query})
+		})
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("cmdFind(--query %s) error = %v, want %q", tt.query, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("cmdFind(--query %s) error = %v", tt.query, err)
+		}
+		if out != tt.want {
+			t.Fatalf("cmdFind(--query %s) = %q, want %q", tt.query, out, tt.want)
+		}
+	}
+}
+
+func TestCmdFind_MultiOutput(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "bm.tsv")
+	if err := bookmarks.Save(storePath, promptEntries()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--query", "tag:work", "--multi"}, want: "api\nweb\n"},
+		{args: []string{"--query", "tag:work", "--multi", "--paths"}, want: "/src/api\n/src/web\n"},
+		{args: []string{"--query", "tag:work", "--multi", "-0"}, want: "api\x00web\x00"},
+		{args: []string{"--query", "web", "--paths", "--print0"}, want: "/src/web\x00"},
+		{args: []string{"--query", "web", "--print", "path"}, want: "/src/web\n"},
+		{args: []string{"--query", "web", "--print=name"}, want: "web\n"},
+		{args: []string{"--query", "web", "--print", "cd"}, want: "cd -- '/src/web'\n"},
+		{args: []string{"--query", "web", "--print", "GO"}, want: "bm go 'web'\n"},
+		{args: []string{"--query", "tag:work", "--multi", "--print", "cd"}, want: "cd -- '/src/api'\ncd -- '/src/web'\n"},
+	}
+	for _, tt := range tests {
+		out, err := captureStdout(t, func() error {
+			return withStdin(t, "1 2\n", func() error {
+				return cmdFind(storePath, append([]string{"--no-tui"}, tt.args...))
+			})
+		})
+		if err != nil {
+			t.Fatalf("cmdFind(%v) error = %v", tt.args, err)
+		}
+		if out != tt.want {
+			t.Fatalf("cmdFind(%v) = %q, want %q", tt.args, out, tt.want)
+		}
+	}
+}
+
+func TestCmdFind_PrintModeErrors(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "bm.tsv")
+	if err := bookmarks.Save(storePath, promptEntries()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--print", "url"}, want: "unknown --print mode: url (expected path, name, cd or go)"},
+		{args: []string{"--print", "cd", "--paths"}, want: "--paths is short for --print path"},
+		{args: []string{"--spawn-shell", "--print", "cd"}, want: "--spawn-shell does not print anything"},
+		{args: []string{"--spawn-shell", "--multi"}, want: "drop --multi"},
+	}
+	for _, tt := range tests {
+		err := cmdFind(storePath, append([]string{"--query", "web"}, tt.args...))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("cmdFind(%v) error = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+	if err := cmdTable(storePath, []string{"--print", "url"}); err == nil || !strings.Contains(err.Error(), "unknown --print mode") {
+		t.Fatalf("cmdTable(--print url) error = %v", err)
+	}
+}
+
+func TestCmdFind_SpawnShell(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "bm.tsv")
+	if err := bookmarks.Save(storePath, promptEntries()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	var dirs []string
+	orig := spawnShell
+	spawnShell = func(dir string) error {
+		dirs = append(dirs, dir)
+		return nil
+	}
+	t.Cleanup(func() { spawnShell = orig })
+
+	out, err := captureStdout(t, func() error {
+		return cmdFind(storePath, []string{"--query", "web", "--spawn-shell"})
+	})
+	if err != nil || out != "" || !reflect.DeepEqual(dirs, []string{"/src/web"}) {
+		t.Fatalf("cmdFind(--spawn-shell) = %q, %v, spawned in %v", out, err, dirs)
+	}
+}
+
+func TestSpawnShell(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SHELL", "/bin/sh")
+	// The shell's exit status is the user's business, not an error.
+	out, err := captureStdout(t, func() error {
+		return withStdin(t, "pwd\nexit 3\n", func() error { return spawnShell(dir) })
+	})
+	if err != nil || strings.TrimSpace(out) != dir {
+		t.Fatalf("spawnShell() = %q, %v", out, err)
+	}
+	if err := spawnShell(filepath.Join(dir, "missing")); err == nil {
+		t.Fatalf("spawnShell() in a missing directory succeeded")
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+// cmdRecord counts a visit to dir for every bookmark containing it or, when
+// none does and record.hot_dirs is set, in the hot directory log. The shell
+// integration calls it on every directory change, so it never touches the
+// store file and only runs git inside a work tree.
+func cmdRecord(storePath string, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: bm record <dir>")
+	}
+	dir, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+
+	entries, err := bookmarks.Load(storePath)
+	if err != nil {
+		return err
+	}
+	resolved := entries
+	if hasRepoRelative(entries) && inWorkTree(dir) {
+		resolved = resolveRepoPathsFrom(entries, dir)
+	}
+	var hits []string
+	for i := range entries {
+		if bookmarks.Within(dir, entries[i].Path) || bookmarks.Within(dir, resolved[i].Path) {
+			hits = append(hits, entries[i].Path)
+		}
+	}
+
+	now := time.Now()
+	if len(hits) > 0 {
+		path, err := bookmarks.DefaultVisitsPath()
+		if err != nil {
+			return err
+		}
+		return bookmarks.UpdateVisits(path, 0, func(visits bookmarks.Visits) {
+			seen := map[string]bool{}
+			for _, hit := range hits {
+				if !seen[hit] {
+					seen[hit] = true
+					visits.Record(hit, now)
+				}
+			}
+		})
+	}
+
+	cfg, err := loadConfig()
+	if err != nil || cfg.HotDirs == 0 {
+		return err
+	}
+	path, err := bookmarks.DefaultHotDirsPath()
+	if err != nil {
+		return err
+	}
+	return bookmarks.UpdateVisits(path, cfg.HotDirs, func(hot bookmarks.Visits) {
+		hot.Record(dir, now)
+	})
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+func TestCmdRecord_CountsBookmarksAndHotDirs(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	root := t.TempDir()
+	api := filepath.Join(root, "src", "api")
+	handlers := filepath.Join(api, "handlers")
+	other := filepath.Join(root, "other")
+	for _, dir := range []string{handlers, other} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	storePath := filepath.Join(root, "bm.tsv")
+	if err := cmdAdd(storePath, []string{"api", api}); err != nil {
+		t.Fatalf("cmdAdd() error = %v", err)
+	}
+
+	for _, dir := range []string{api, handlers, other} {
+		if err := cmdRecord(storePath, []string{dir}); err != nil {
+			t.Fatalf("cmdRecord(%s) error = %v", dir, err)
+		}
+	}
+	visitsPath, _ := bookmarks.DefaultVisitsPath()
+	visits, err := bookmarks.LoadVisits(visitsPath)
+	if err != nil {
+		t.Fatalf("LoadVisits() error = %v", err)
+	}
+	if len(visits) != 1 || visits[api].Count != 2 {
+		t.Fatalf("visits = %#v", visits)
+	}
+	hotPath, _ := bookmarks.DefaultHotDirsPath()
+	if _, err := os.Stat(hotPath); !os.IsNotExist(err) {
+		t.Fatalf("hot dirs written without record.hot_dirs: %v", err)
+	}
+
+	writeConfig(t, "record.hot_dirs = 10\n")
+	if err := cmdRecord(storePath, []string{other}); err != nil {
+		t.Fatalf("cmdRecord() error = %v", err)
+	}
+	hot, err := bookmarks.LoadVisits(hotPath)
+	if err != nil {
+		t.Fatalf("LoadVisits(hot) error = %v", err)
+	}
+	if len(hot) != 1 || hot[other].Count != 1 {
+		t.Fatalf("hot = %#v", hot)
+	}
+}
+
+func TestCmdInit_Record(t *testing.T) {
+	tests := map[string][]string{
+		"bash":   {"$PROMPT_COMMAND;}__bm_record\"", "command bm record \"$PWD\""},
+		"zsh":    {"add-zsh-hook chpwd __bm_record", "command bm record \"$PWD\""},
+		"fish":   {"function __bm_record --on-variable PWD", "command bm record \"$PWD\""},
+		"pwsh":   {"function global:prompt", "record $loc.ProviderPath"},
+		"nu":     {"hooks.env_change.PWD", "^bm record $dir"},
+		"elvish": {"set after-chdir", "e:bm record $pwd"},
+		"xonsh":  {"@events.on_chdir", "_bm_run(\"record\", newdir"},
+	}
+	for shell, want := range tests {
+		out, err := captureStdout(t, func() error { return cmdInit([]string{shell, "--record"}) })
+		if err != nil {
+			t.Fatalf("cmdInit(%s --record) error = %v", shell, err)
+		}
+		if !strings.Contains(out, want[0]) || !strings.Contains(out, want[1]) {
+			t.Fatalf("cmdInit(%s --record) missing hook, got %q", shell, out)
+		}
+		out, _ = captureStdout(t, func() error { return cmdInit([]string{shell}) })
+		if strings.Contains(out, want[1]) {
+			t.Fatalf("cmdInit(%s) includes record hook without --record", shell)
+		}
+	}
+}
+
+func TestCmdRecord_RepoRelativeWorktree(t *testing.T) {
+	requireGit(t)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	root := t.TempDir()
+	app := filepath.Join(root, "app")
+	feature := filepath.Join(root, "app-feature")
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main", app},
+		{"-C", app, "commit", "-q", "--allow-empty", "-m", "init"},
+		{"-C", app, "worktree", "add", "-q", "-b", "feature", feature},
+	} {
+		mustGit(t, args...)
+	}
+	storePath := filepath.Join(root, "bm.tsv")
+	if err := cmdAdd(storePath, []string{"app", app, "--repo-relative"}); err != nil {
+		t.Fatalf("cmdAdd() error = %v", err)
+	}
+
+	// The visited directory, not the process's, picks the worktree.
+	for _, dir := range []string{feature, root} {
+		if err := cmdRecord(storePath, []string{dir}); err != nil {
+			t.Fatalf("cmdRecord(%s) error = %v", dir, err)
+		}
+	}
+	visitsPath, _ := bookmarks.DefaultVisitsPath()
+	visits, err := bookmarks.LoadVisits(visitsPath)
+	if err != nil {
+		t.Fatalf("LoadVisits() error = %v", err)
+	}
+	if len(visits) != 1 || visits[app].Count != 1 {
+		t.Fatalf("visits = %#v", visits)
+	}
+}
+
+func TestInWorkTree(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "repo", "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if inWorkTree(sub) {
+		t.Fatalf("inWorkTree(%s) = true before .git exists", sub)
+	}
+	// Linked worktrees have a .git file rather than a directory.
+	if err := os.WriteFile(filepath.Join(root, "repo", ".git"), []byte("gitdir: /elsewhere\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if !inWorkTree(sub) {
+		t.Fatalf("inWorkTree(%s) = false below a .git file", sub)
+	}
+}
+
+func TestShellRecord_BashKeepsExitStatus(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+	root, proj, env := shellTestEnv(t)
+	// The hook runs after the existing prompt commands and passes the status
+	// of the user's last command on, whether or not it records a visit.
+	script := `PROMPT_COMMAND='echo before=$?'
+eval "$(bm init bash --record)"
+cd "` + proj + `"
+false
+eval "$PROMPT_COMMAND"
+cd /
+false
+__bm_record
+echo after=$?
+false
+__bm_record
+echo again=$?
+`
+	cmd := exec.Command(bash, "-c", script)
+	cmd.Dir = root
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash error = %v\n%s", err, out)
+	}
+	if want := "before=1\nafter=1\nagain=1\n"; string(out) != want {
+		t.Fatalf("bash printed %q, want %q", out, want)
+	}
+	visits, err := bookmarks.LoadVisits(filepath.Join(root, "state", "bm", "visits"))
+	if err != nil || visits[proj].Count != 1 {
+		t.Fatalf("visits = %#v, %v", visits, err)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+	"github.com/navio/bookmarks/internal/gitinfo"
+)
+
+// repoRelative returns the repository ID and the path relative to the work
+// tree root for a directory inside a git repository.
+func repoRelative(dir string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+	repo, err := gitinfo.RepoOf(ctx, dir)
+	if errors.Is(err, gitinfo.ErrNotRepo) {
+		return "", "", fmt.Errorf("not inside a git work tree: %s", dir)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	// Look the ID up afresh, refreshing the cache in case the common
+	// directory now holds a different repository.
+	id, err := gitinfo.RootCommit(ctx, repo.Root)
+	if errors.Is(err, gitinfo.ErrNoCommits) {
+		return "", "", fmt.Errorf("repository has no commits yet: %s", repo.Root)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	storeRepoID(repo, id)
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", "", err
+	}
+	rel, err := filepath.Rel(repo.Root, dir)
+	if err != nil {
+		return "", "", err
+	}
+	return id, filepath.ToSlash(rel), nil
+}
+
+// resolveRepoPaths rewrites the path of each repo-relative bookmark to point
+// into the worktree containing the current directory, when that worktree
+// belongs to the bookmark's repository. Other bookmarks, and repo-relative ones
+// used from outside their repository, keep their stored path.
+func resolveRepoPaths(entries []bookmarks.Bookmark) []bookmarks.Bookmark {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return entries
+	}
+	return resolveRepoPathsFrom(entries, cwd)
+}
+
+// resolveRepoPathsFrom is resolveRepoPaths for the worktree containing dir.
+func resolveRepoPathsFrom(entries []bookmarks.Bookmark, dir string) []bookmarks.Bookmark {
+	if !hasRepoRelative(entries) {
+		return entries
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+	repo, err := gitinfo.RepoOf(ctx, dir)
+	if err != nil {
+		return entries
+	}
+	id, ok := cachedRepoID(ctx, repo)
+	if !ok {
+		return entries
+	}
+
+	resolved := make([]bookmarks.Bookmark, len(entries))
+	copy(resolved, entries)
+	for i := range resolved {
+		if resolved[i].IsRepoRelative() && resolved[i].Repo == id {
+			resolved[i].Path = filepath.Join(repo.Root, filepath.FromSlash(resolved[i].RepoPath))
+		}
+	}
+	return resolved
+}
+
+func hasRepoRelative(entries []bookmarks.Bookmark) bool {
+	for _, e := range entries {
+		if e.IsRepoRelative() {
+			return true
+		}
+	}
+	return false
+}
+
+// inWorkTree reports whether dir or one of its parents has a .git entry. It
+// lets callers on hot paths skip running git outside repositories.
+func inWorkTree(dir string) bool {
+	for {
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			return true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
+
+// cachedRepoID returns the ID of repo from the cache in the state directory,
+// looking it up and caching it the first time the repository is seen.
+func cachedRepoID(ctx context.Context, repo gitinfo.Repo) (string, bool) {
+	if path, err := bookmarks.DefaultRepoIDsPath(); err == nil {
+		if ids, err := bookmarks.LoadRepoIDs(path); err == nil && ids[repo.CommonDir] != "" {
+			return ids[repo.CommonDir], true
+		}
+	}
+	id, err := gitinfo.RootCommit(ctx, repo.Root)
+	if err != nil {
+		return "", false
+	}
+	storeRepoID(repo, id)
+	return id, true
+}
+
+// storeRepoID caches the ID of repo. The cache only saves work, so failing to
+// write it is not an error.
+func storeRepoID(repo gitinfo.Repo, id string) {
+	path, err := bookmarks.DefaultRepoIDsPath()
+	if err != nil {
+		return
+	}
+	ids, err := bookmarks.LoadRepoIDs(path)
+	if err != nil {
+		ids = bookmarks.RepoIDs{}
+	}
+	if ids[repo.CommonDir] == id {
+		return
+	}
+	ids[repo.CommonDir] = id
+	_ = bookmarks.SaveRepoIDs(path, ids)
+}
+
+func bookmarkMode(entry bookmarks.Bookmark) string {
+	if entry.IsRepoRelative() {
+		return "repo"
+	}
+	return "abs"
+}
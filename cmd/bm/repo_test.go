@@ -0,0 +1,136 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+func TestCmdGo_RepoRelativeFollowsWorktree(t *testing.T) {
+	requireGit(t)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	root := t.TempDir()
+	app := filepath.Join(root, "app")
+	feature := filepath.Join(root, "app-feature")
+	clone := filepath.Join(root, "app-clone")
+	handlers := filepath.Join(app, "api", "handlers")
+	if err := os.MkdirAll(handlers, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(handlers, "h.go"), []byte("package handlers\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for _, args := range [][]string{
+		{"-C", app, "init", "-q", "-b", "main"},
+		{"-C", app, "add", "."},
+		{"-C", app, "commit", "-q", "-m", "init"},
+		{"-C", app, "worktree", "add", "-q", "-b", "feature", feature},
+		{"clone", "-q", app, clone},
+	} {
+		mustGit(t, args...)
+	}
+
+	storePath := filepath.Join(root, "bm.tsv")
+	if err := cmdAdd(storePath, []string{"api-handlers", handlers, "--repo-relative"}); err != nil {
+		t.Fatalf("cmdAdd() error = %v", err)
+	}
+	if err := cmdAdd(storePath, []string{"plain", handlers}); err != nil {
+		t.Fatalf("cmdAdd() error = %v", err)
+	}
+	entries, err := bookmarks.Load(storePath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	rootCommit := strings.TrimSpace(mustGit(t, "-C", app, "rev-list", "--max-parents=0", "HEAD"))
+	if entries[0].RepoPath != "api/handlers" || entries[0].Repo != rootCommit {
+		t.Fatalf("repo fields = %q, %q", entries[0].Repo, entries[0].RepoPath)
+	}
+
+	oldCwd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(oldCwd) })
+
+	tests := []struct {
+		cwd  string
+		name string
+		want string
+	}{
+		{cwd: feature, name: "api-handlers", want: filepath.Join(feature, "api", "handlers")},
+		{cwd: clone, name: "api-handlers", want: filepath.Join(clone, "api", "handlers")},
+		{cwd: app, name: "api-handlers", want: handlers},
+		{cwd: root, name: "api-handlers", want: handlers},
+		{cwd: feature, name: "plain", want: handlers},
+	}
+	for _, tt := range tests {
+		if err := os.Chdir(tt.cwd); err != nil {
+			t.Fatalf("chdir: %v", err)
+		}
+		out, err := captureStdout(t, func() error { return cmdPath(storePath, []string{tt.name}) })
+		if err != nil {
+			t.Fatalf("cmdPath(%s) from %s error = %v", tt.name, tt.cwd, err)
+		}
+		if got := strings.TrimSpace(out); got != tt.want {
+			t.Fatalf("cmdPath(%s) from %s = %q, want %q", tt.name, tt.cwd, got, tt.want)
+		}
+	}
+
+	out, err := captureStdout(t, func() error { return cmdList(storePath, nil) })
+	if err != nil {
+		t.Fatalf("cmdList() error = %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if n := len(strings.Split(line, "\t")); n != 4 {
+			t.Fatalf("ls line has %d columns, want 4: %q", n, line)
+		}
+	}
+	out, err = captureStdout(t, func() error { return cmdList(storePath, []string{"--long"}) })
+	if err != nil {
+		t.Fatalf("cmdList(--long) error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if !strings.HasSuffix(lines[0], "\trepo") || !strings.HasSuffix(lines[1], "\tabs") {
+		t.Fatalf("ls --long output = %q", out)
+	}
+
+	// Root commits are looked up once per repository and then read from the
+	// cache, keyed by common directory.
+	idsPath, _ := bookmarks.DefaultRepoIDsPath()
+	ids, err := bookmarks.LoadRepoIDs(idsPath)
+	if err != nil {
+		t.Fatalf("LoadRepoIDs() error = %v", err)
+	}
+	cloneGit := filepath.Join(clone, ".git")
+	if ids[filepath.Join(app, ".git")] != entries[0].Repo || ids[cloneGit] != entries[0].Repo {
+		t.Fatalf("repo ids = %#v", ids)
+	}
+	ids[cloneGit] = "0000000000000000000000000000000000000000"
+	if err := bookmarks.SaveRepoIDs(idsPath, ids); err != nil {
+		t.Fatalf("SaveRepoIDs() error = %v", err)
+	}
+	if err := os.Chdir(clone); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	out, err = captureStdout(t, func() error { return cmdPath(storePath, []string{"api-handlers"}) })
+	if err != nil || strings.TrimSpace(out) != handlers {
+		t.Fatalf("cmdPath(api-handlers) with a cached foreign id = %q, %v", out, err)
+	}
+
+	if err := cmdUpdate(storePath, []string{"api-handlers", "--absolute"}); err != nil {
+		t.Fatalf("cmdUpdate(--absolute) error = %v", err)
+	}
+	if entries, _ := bookmarks.Load(storePath); entries[0].IsRepoRelative() {
+		t.Fatalf("entry still repo-relative: %#v", entries[0])
+	}
+}
+
+func TestCmdAdd_RepoRelativeOutsideRepo(t *testing.T) {
+	requireGit(t)
+	dir := t.TempDir()
+	err := cmdAdd(filepath.Join(dir, "bm.tsv"), []string{"x", dir, "--repo-relative"})
+	if err == nil || !strings.Contains(err.Error(), "not inside a git work tree") {
+		t.Fatalf("cmdAdd() error = %v", err)
+	}
+}
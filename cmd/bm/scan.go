@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+func cmdScan(storePath string, args []string) error {
+	const usageScan = "usage: bm scan <root> [--depth N] [--yes] [--dry-run] [--theme name]"
+
+	positionals, err := parseArgs(args, map[string]bool{"--depth": true, "--yes": false, "--dry-run": false, "--theme": true})
+	if err != nil {
+		return err
+	}
+	if len(positionals.args) != 1 {
+		return errors.New(usageScan)
+	}
+	depth := 3
+	if v, ok := positionals.flags["--depth"]; ok {
+		depth, err = strconv.Atoi(v)
+		if err != nil || depth < 0 {
+			return fmt.Errorf("--depth expects a non-negative number, got %q", v)
+		}
+	}
+	_, acceptAll := positionals.flags["--yes"]
+	_, dryRun := positionals.flags["--dry-run"]
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	root, err := bookmarks.ResolvePath(positionals.args[0], cwd)
+	if err != nil {
+		return err
+	}
+
+	entries, err := bookmarks.Load(storePath)
+	if err != nil {
+		return err
+	}
+	candidates, err := bookmarks.Scan(root, depth, entries)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	themeName := themeFlag(positionals.flags, cfg)
+	if err := checkTheme(themeName); err != nil {
+		return err
+	}
+	for i := range candidates {
+		candidates[i].Tags = bookmarks.AddTags(candidates[i].Tags, bookmarks.ApplyRules(cfg.Rules, candidates[i].Path))
+	}
+	if len(candidates) == 0 {
+		fmt.Fprintln(os.Stderr, "no new projects found")
+		return nil
+	}
+
+	if dryRun {
+		for _, c := range candidates {
+			fmt.Printf("%s\t%s\t%s\n", c.Name, c.Path, strings.Join(c.Tags, ","))
+		}
+		return nil
+	}
+
+	chosen := candidates
+	if !acceptAll {
+		items := make([]pickItem, 0, len(candidates))
+		for _, c := range candidates {
+			desc := c.Path
+			if len(c.Tags) > 0 {
+				desc += "  [" + strings.Join(c.Tags, ",") + "]"
+			}
+			items = append(items, pickItem{key: c.Path, title: c.Name, desc: desc})
+		}
+		if err := setupTheme(themeName); err != nil {
+			return err
+		}
+		keys, err := runPickTUI(items, "bm scan "+root, true)
+		if err != nil {
+			return err
+		}
+		chosen = selectCandidates(candidates, keys)
+	}
+	if len(chosen) == 0 {
+		return nil
+	}
+
+	return addCandidates(storePath, entries, chosen, "scan "+root)
+}
+
+// addCandidates appends the chosen candidates to entries and saves them in a
+// single write.
+func addCandidates(storePath string, entries []bookmarks.Bookmark, chosen []bookmarks.Candidate, message string) error {
+	now := time.Now().UTC()
+	for _, c := range chosen {
+		entries = append(entries, bookmarks.Bookmark{
+			Name:      c.Name,
+			Path:      c.Path,
+			Tags:      c.Tags,
+			CreatedAt: now,
+		})
+	}
+	if err := saveStore(storePath, entries, fmt.Sprintf("%s: add %d bookmark(s)", message, len(chosen))); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "added %d bookmark(s)\n", len(chosen))
+	return nil
+}
+
+func selectCandidates(candidates []bookmarks.Candidate, keys []string) []bookmarks.Candidate {
+	wanted := map[string]bool{}
+	for _, k := range keys {
+		wanted[k] = true
+	}
+	var chosen []bookmarks.Candidate
+	for _, c := range candidates {
+		if wanted[c.Path] {
+			chosen = append(chosen, c)
+		}
+	}
+	return chosen
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+func TestCmdScan_YesAddsAllInOneWrite(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"src/api/go.mod", "src/web/package.json", "src/notes/readme.txt"} {
+		p := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	storePath := filepath.Join(root, "bm.tsv")
+
+	out, err := captureStdout(t, func() error {
+		return cmdScan(storePath, []string{filepath.Join(root, "src"), "--dry-run"})
+	})
+	if err != nil {
+		t.Fatalf("cmdScan(--dry-run) error = %v", err)
+	}
+	if !strings.Contains(out, "api\t"+filepath.Join(root, "src", "api")+"\tlang/go\n") {
+		t.Fatalf("dry-run output missing api: %q", out)
+	}
+	if entries, _ := bookmarks.Load(storePath); len(entries) != 0 {
+		t.Fatalf("dry-run wrote %d entries", len(entries))
+	}
+
+	if err := cmdScan(storePath, []string{filepath.Join(root, "src"), "--yes"}); err != nil {
+		t.Fatalf("cmdScan(--yes) error = %v", err)
+	}
+	entries, err := bookmarks.Load(storePath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Name != "api" || entries[1].Name != "web" {
+		t.Fatalf("entries = %#v", entries)
+	}
+	if !bookmarks.ContainsTag(entries[1].Tags, "lang/node") {
+		t.Fatalf("web tags = %#v", entries[1].Tags)
+	}
+
+	// A second scan finds nothing new.
+	out, err = captureStdout(t, func() error {
+		return cmdScan(storePath, []string{filepath.Join(root, "src"), "--dry-run"})
+	})
+	if err != nil || out != "" {
+		t.Fatalf("rescan = %q, %v; want nothing", out, err)
+	}
+}
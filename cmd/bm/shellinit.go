@@ -0,0 +1,556 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Every init script is made of sections: the internal functions (named
+// __bm_*), the `bm` wrapper, the cd/go helpers and, for shells with a line
+// editor bm knows, a keybinding widget. The wrapper and helpers only call the
+// internal functions and the bm executable, so they can be left out or
+// renamed.
+//
+// The sh and fish scripts evaluate the `cd` command printed by `bm go`.
+// PowerShell, Nushell, Elvish and Xonsh cannot evaluate sh, so they change
+// directory themselves to the path printed by `bm path`. All of them ask the
+// pickers for the chosen name with --print name.
+
+// shellInitOptions are the bm init flags shaping the script.
+type shellInitOptions struct {
+	// prefix names the wrapper <prefix> and the helpers <prefix>cd and
+	// <prefix>go.
+	prefix string
+	// wrapBM is false when the bm wrapper is left out. A renamed wrapper
+	// never shadows bm, so it is always kept.
+	wrapBM bool
+	// bind is the key opening the picker widget, when set.
+	bind *bindKey
+}
+
+// bindKey is a ctrl-<letter> or alt-<letter> key.
+type bindKey struct {
+	alt    bool
+	letter byte
+}
+
+var shellPrefixPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func parseShellInitOptions(flags map[string]string) (shellInitOptions, error) {
+	opts := shellInitOptions{prefix: "bm", wrapBM: true}
+	if v, ok := flags["--cmd"]; ok {
+		if !shellPrefixPattern.MatchString(v) {
+			return opts, fmt.Errorf("invalid --cmd prefix: %q (expected letters, digits and underscores)", v)
+		}
+		opts.prefix = v
+	}
+	if _, ok := flags["--no-wrap-bm"]; ok {
+		opts.wrapBM = false
+	}
+	if v, ok := flags["--bind"]; ok {
+		key, err := parseBindKey(v)
+		if err != nil {
+			return opts, err
+		}
+		opts.bind = &key
+	}
+	return opts, nil
+}
+
+func parseBindKey(s string) (bindKey, error) {
+	mod, letter, ok := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "-")
+	if ok && len(letter) == 1 && letter[0] >= 'a' && letter[0] <= 'z' {
+		switch mod {
+		case "ctrl":
+			return bindKey{letter: letter[0]}, nil
+		case "alt":
+			return bindKey{alt: true, letter: letter[0]}, nil
+		}
+	}
+	return bindKey{}, fmt.Errorf("invalid --bind key: %q (expected ctrl-<letter> or alt-<letter>)", s)
+}
+
+// shellInitScript assembles the init script of a supported shell.
+func shellInitScript(shellName string, opts shellInitOptions) (string, error) {
+	var core, wrapper, helpers, widget string
+	switch shellName {
+	case "bash", "zsh":
+		core, wrapper, helpers = shInitCore, shInitWrapper, shInitHelpers
+		if opts.bind != nil {
+			widget = shellWidgetSh(shellName, *opts.bind)
+		}
+	case "fish":
+		core, wrapper, helpers = fishInitCore, fishInitWrapper, fishInitHelpers
+		if opts.bind != nil {
+			widget = shellWidgetFish(*opts.bind)
+		}
+	case "pwsh":
+		core, wrapper, helpers = pwshInitCore, pwshInitWrapper, pwshInitHelpers
+	case "nu":
+		core, wrapper, helpers = nuInitCore, nuInitWrapper, nuInitHelpers
+	case "elvish":
+		core, wrapper, helpers = elvishInitCore, elvishInitWrapper, elvishInitHelpers
+	case "xonsh":
+		core, wrapper, helpers = xonshInitCore, xonshInitWrapper, xonshInitHelpers
+	default:
+		return "", fmt.Errorf("unsupported shell: %s (expected %s)", shellName, supportedShells)
+	}
+	if opts.bind != nil && widget == "" {
+		return "", errors.New("--bind is only supported for bash, zsh and fish")
+	}
+
+	sections := []string{core}
+	if opts.wrapBM || opts.prefix != "bm" {
+		sections = append(sections, renameWrapper(wrapper, opts.prefix))
+	}
+	// The helper templates use the default names; the internal functions
+	// never contain them.
+	sections = append(sections, strings.NewReplacer("bmcd", opts.prefix+"cd", "bmgo", opts.prefix+"go").Replace(helpers))
+	if widget != "" {
+		sections = append(sections, widget)
+	}
+	for i, s := range sections {
+		sections[i] = strings.TrimLeft(s, "\n")
+	}
+	return strings.Join(sections, "\n"), nil
+}
+
+const shInitCore = `
+__bm_go() {
+  local cmd hook
+  cmd="$(command bm go "$@")" || return
+  eval "$cmd" || return
+  hook="$(command bm hook "$@")" || return
+  if [ -n "$hook" ]; then
+    eval "$hook"
+  fi
+}
+`
+
+// renameWrapper names the wrapper defined by a wrapper template. Only the
+// definitions are renamed: the body keeps calling the bm executable.
+func renameWrapper(wrapper, name string) string {
+	if name == "bm" {
+		return wrapper
+	}
+	return strings.NewReplacer(
+		"\nbm() {", "\n"+name+"() {",
+		"\nfunction bm\n", "\nfunction "+name+"\n",
+		"function global:bm {", "function global:"+name+" {",
+		"def --env --wrapped bm [", "def --env --wrapped "+name+" [",
+		"\nfn bm {", "\nfn "+name+" {",
+		"edit:add-var bm~ $bm~", "edit:add-var "+name+"~ $"+name+"~",
+		`aliases["bm"]`, `aliases["`+name+`"]`,
+	).Replace(wrapper)
+}
+
+const shInitWrapper = `
+bm() {
+  if [ "$1" = "go" ]; then
+    shift
+    __bm_go "$@"
+    return
+  fi
+  if [ "$1" = "find" ] || [ "$1" = "table" ] || [ "$1" = "tui" ]; then
+    case " $* " in
+      *" --multi "*|*" --paths "*|*" --print "*|*" --print="*|*" --spawn-shell "*|*" -0 "*|*" --print0 "*)
+        command bm "$@"
+        return
+        ;;
+    esac
+    local name
+    name="$(command bm "$@" --print name)" || return
+    if [ -n "$name" ]; then
+      __bm_go "$name"
+    fi
+    return
+  fi
+  command bm "$@"
+}
+`
+
+const shInitHelpers = `
+bmcd() {
+  local dir
+  dir="$(command bm find --paths "$@")" || return
+  if [ -n "$dir" ]; then
+    cd "$dir"
+  fi
+}
+
+bmgo() {
+  if [ "$#" -ne 1 ]; then
+    printf 'usage: bmgo <name>\n' >&2
+    return 1
+  fi
+  __bm_go "$1"
+}
+`
+
+// shellWidgetSh opens the picker from a zle widget or a readline binding and
+// changes directory without touching the command line being edited. Line
+// editors give widgets no terminal on stdin, so the picker reads /dev/tty.
+func shellWidgetSh(shellName string, key bindKey) string {
+	if shellName == "zsh" {
+		seq := "^" + strings.ToUpper(string(key.letter))
+		if key.alt {
+			seq = "^[" + string(key.letter)
+		}
+		return `
+__bm_widget() {
+  local name precmd
+  name="$(command bm find --print name </dev/tty)"
+  if [ -n "$name" ]; then
+    __bm_go "$name"
+    for precmd in $precmd_functions; do
+      "$precmd"
+    done
+  fi
+  zle reset-prompt
+}
+
+if [[ -o interactive ]]; then
+  zle -N __bm_widget
+  bindkey '` + seq + `' __bm_widget
+fi
+`
+	}
+	seq := `\C-` + string(key.letter)
+	if key.alt {
+		seq = `\e` + string(key.letter)
+	}
+	return `
+__bm_widget() {
+  local name
+  name="$(command bm find --print name </dev/tty)" || return
+  if [ -n "$name" ]; then
+    __bm_go "$name"
+  fi
+}
+
+if [[ $- == *i* ]]; then
+  bind -x '"` + seq + `": __bm_widget'
+fi
+`
+}
+
+const fishInitCore = `
+function __bm_go
+  set -l cmd (command bm go $argv)
+  or return
+  eval $cmd
+  or return
+  set -l hook (command bm hook $argv)
+  or return
+  if test -n "$hook"
+    eval (string join \n -- $hook)
+  end
+end
+`
+
+const fishInitWrapper = `
+function bm
+  if test (count $argv) -gt 0
+    if test "$argv[1]" = "go"
+      set -e argv[1]
+      __bm_go $argv
+      return
+    end
+    if test "$argv[1]" = "find"; or test "$argv[1]" = "table"; or test "$argv[1]" = "tui"
+      if contains -- --multi $argv; or contains -- --paths $argv; or contains -- --print $argv; or string match -q -- '--print=*' $argv; or contains -- --spawn-shell $argv; or contains -- -0 $argv; or contains -- --print0 $argv
+        command bm $argv
+        return
+      end
+      set -l name (command bm $argv --print name)
+      or return
+      if test -n "$name"
+        __bm_go $name
+      end
+      return
+    end
+  end
+  command bm $argv
+end
+`
+
+const fishInitHelpers = `
+function bmcd
+  set -l dir (command bm find --paths $argv)
+  or return
+  if test -n "$dir"
+    cd "$dir"
+  end
+end
+
+function bmgo
+  if test (count $argv) -ne 1
+    echo "usage: bmgo <name>" >&2
+    return 1
+  end
+  __bm_go $argv[1]
+end
+`
+
+func shellWidgetFish(key bindKey) string {
+	seq := `\c` + string(key.letter)
+	if key.alt {
+		seq = `\e` + string(key.letter)
+	}
+	return `
+function __bm_widget
+  set -l name (command bm find --print name </dev/tty)
+  if test -n "$name"
+    __bm_go $name
+  end
+  commandline -f repaint
+end
+
+if status is-interactive
+  bind ` + seq + ` __bm_widget
+end
+`
+}
+
+const pwshInitCore = `
+function global:__bm_exe {
+  Get-Command -Name bm -CommandType Application -ErrorAction Stop | Select-Object -First 1
+}
+
+function global:__bm_go {
+  $bm = __bm_exe
+  $dir = & $bm path @args
+  if ($LASTEXITCODE -ne 0 -or -not $dir) { return }
+  Set-Location -LiteralPath $dir
+  # Hooks are sh commands; bm hook only warns about them here.
+  & $bm hook --shell pwsh @args
+}
+`
+
+const pwshInitWrapper = `
+function global:bm {
+  $bm = __bm_exe
+  if ($args.Count -gt 0 -and $args[0] -eq 'go') {
+    $rest = @($args | Select-Object -Skip 1)
+    __bm_go @rest
+    return
+  }
+  if ($args.Count -gt 0 -and $args[0] -in 'find', 'table', 'tui') {
+    foreach ($arg in $args) {
+      if ($arg -in '--multi', '--paths', '--print', '--spawn-shell', '-0', '--print0' -or "$arg" -like '--print=*') {
+        & $bm @args
+        return
+      }
+    }
+    $name = & $bm @args --print name
+    if ($LASTEXITCODE -ne 0 -or -not $name) { return }
+    __bm_go $name
+    return
+  }
+  & $bm @args
+}
+`
+
+const pwshInitHelpers = `
+function global:bmcd {
+  $bm = __bm_exe
+  $dir = & $bm find --paths @args
+  if ($LASTEXITCODE -ne 0 -or -not $dir) { return }
+  Set-Location -LiteralPath $dir
+}
+
+function global:bmgo {
+  if ($args.Count -ne 1) {
+    Write-Error 'usage: bmgo <name>'
+    return
+  }
+  __bm_go $args[0]
+}
+`
+
+const nuInitCore = `
+def --env __bm_go [...args: string] {
+  let dir = (^bm path ...$args | str trim)
+  cd $dir
+  # Hooks are sh commands; bm hook only warns about them here.
+  ^bm hook --shell nu ...$args
+}
+`
+
+const nuInitWrapper = `
+def --env --wrapped bm [...args: string] {
+  let sub = if ($args | is-empty) { "" } else { $args | first }
+  if $sub == "go" {
+    __bm_go ...($args | skip 1)
+    return
+  }
+  if $sub in ["find" "table" "tui"] {
+    let printing = ($args | any {|arg| ($arg in ["--multi" "--paths" "--print" "--spawn-shell" "-0" "--print0"]) or ($arg | str starts-with "--print=") })
+    if $printing {
+      ^bm ...$args
+      return
+    }
+    let name = (^bm ...$args --print name | str trim)
+    if $name != "" {
+      __bm_go $name
+    }
+    return
+  }
+  ^bm ...$args
+}
+`
+
+const nuInitHelpers = `
+def --env --wrapped bmcd [...args: string] {
+  let dir = (^bm find --paths ...$args | str trim)
+  if $dir != "" {
+    cd $dir
+  }
+}
+
+def --env bmgo [name: string] {
+  __bm_go $name
+}
+`
+
+const elvishInitCore = `
+use str
+
+fn __bm_go {|@args|
+  var dir = (e:bm path $@args)
+  cd $dir
+  # Hooks are sh commands; bm hook only warns about them here.
+  e:bm hook --shell elvish $@args
+}
+`
+
+const elvishInitWrapper = `
+fn bm {|@args|
+  if (== (count $args) 0) {
+    e:bm
+    return
+  }
+  if (eq $args[0] go) {
+    var rest = $args[1..]
+    __bm_go $@rest
+    return
+  }
+  if (has-value [find table tui] $args[0]) {
+    for arg $args {
+      if (or (has-value [--multi --paths --print --spawn-shell -0 --print0] $arg) (str:has-prefix $arg '--print=')) {
+        e:bm $@args
+        return
+      }
+    }
+    var name = (str:join '' [(e:bm $@args --print name)])
+    if (!=s $name '') {
+      __bm_go $name
+    }
+    return
+  }
+  e:bm $@args
+}
+
+edit:add-var bm~ $bm~
+`
+
+const elvishInitHelpers = `
+fn bmcd {|@args|
+  var dir = (str:join '' [(e:bm find --paths $@args)])
+  if (!=s $dir '') {
+    cd $dir
+  }
+}
+
+fn bmgo {|@args|
+  if (!= (count $args) 1) {
+    echo 'usage: bmgo <name>' >&2
+    return
+  }
+  __bm_go $args[0]
+}
+
+edit:add-var bmcd~ $bmcd~
+edit:add-var bmgo~ $bmgo~
+`
+
+const xonshInitCore = `
+import os as _bm_os
+import shutil as _bm_shutil
+import subprocess as _bm_subprocess
+import sys as _bm_sys
+
+import xonsh.dirstack as _bm_dirstack
+from xonsh.tools import unthreadable as _bm_unthreadable
+
+
+def _bm_run(*args, capture=False):
+    env = __xonsh__.env
+    exe = _bm_shutil.which("bm", path=_bm_os.pathsep.join(env["PATH"]))
+    if exe is None:
+        print("bm: executable not found in $PATH", file=_bm_sys.stderr)
+        return _bm_subprocess.CompletedProcess(args, 127, "")
+    stdout = _bm_subprocess.PIPE if capture else None
+    return _bm_subprocess.run([exe, *args], stdout=stdout, text=True, env=env.detype())
+
+
+def _bm_cd(path):
+    _, err, code = _bm_dirstack.cd([path])
+    if code:
+        print(err, end="", file=_bm_sys.stderr)
+    return code
+
+
+def _bm_go(args):
+    proc = _bm_run("path", *args, capture=True)
+    if proc.returncode != 0:
+        return proc.returncode
+    code = _bm_cd(proc.stdout.strip())
+    if code:
+        return code
+    # Hooks are sh commands; bm hook only warns about them here.
+    return _bm_run("hook", "--shell", "xonsh", *args).returncode
+`
+
+const xonshInitWrapper = `
+_BM_PRINTING = ("--multi", "--paths", "--print", "--spawn-shell", "-0", "--print0")
+
+
+@_bm_unthreadable
+def _bm(args):
+    if args and args[0] == "go":
+        return _bm_go(args[1:])
+    if args and args[0] in ("find", "table", "tui"):
+        if not any(arg in _BM_PRINTING or arg.startswith("--print=") for arg in args):
+            proc = _bm_run(*args, "--print", "name", capture=True)
+            if proc.returncode != 0:
+                return proc.returncode
+            name = proc.stdout.strip()
+            return _bm_go([name]) if name else 0
+    return _bm_run(*args).returncode
+
+
+aliases["bm"] = _bm
+`
+
+const xonshInitHelpers = `
+@_bm_unthreadable
+def _bmcd(args):
+    proc = _bm_run("find", "--paths", *args, capture=True)
+    if proc.returncode != 0:
+        return proc.returncode
+    path = proc.stdout.strip()
+    return _bm_cd(path) if path else 0
+
+
+def _bmgo(args):
+    if len(args) != 1:
+        print("usage: bmgo <name>", file=_bm_sys.stderr)
+        return 1
+    return _bm_go(args)
+
+
+aliases["bmcd"] = _bmcd
+aliases["bmgo"] = _bmgo
+`
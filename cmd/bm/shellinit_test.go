@@ -0,0 +1,246 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+func TestShellInitScripts_Golden(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "bash", args: []string{"bash"}},
+		{name: "fish", args: []string{"fish"}},
+		{name: "pwsh", args: []string{"pwsh"}},
+		{name: "nu", args: []string{"nu"}},
+		{name: "elvish", args: []string{"elvish"}},
+		{name: "xonsh", args: []string{"xonsh"}},
+		{name: "bash_bind", args: []string{"bash", "--bind", "alt-g"}},
+		{name: "bash_cmd", args: []string{"bash", "--cmd", "j"}},
+		{name: "zsh_cmd_bind", args: []string{"zsh", "--cmd", "j", "--no-wrap-bm", "--bind", "ctrl-g"}},
+		{name: "fish_cmd_bind", args: []string{"fish", "--cmd", "j", "--bind", "Ctrl-G"}},
+		{name: "xonsh_cmd", args: []string{"xonsh", "--cmd", "j", "--no-wrap-bm"}},
+	}
+	for _, tt := range tests {
+		out, err := captureStdout(t, func() error { return cmdInit(tt.args) })
+		if err != nil {
+			t.Fatalf("cmdInit(%v) error = %v", tt.args, err)
+		}
+		assertGolden(t, "init_"+tt.name, out)
+	}
+}
+
+func TestCmdInit_OptionErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"bash", "--cmd", "j-"}, want: `invalid --cmd prefix: "j-"`},
+		{args: []string{"bash", "--bind", "ctrl-space"}, want: `invalid --bind key: "ctrl-space" (expected ctrl-<letter> or alt-<letter>)`},
+		{args: []string{"bash", "--bind", "g"}, want: `invalid --bind key: "g"`},
+		{args: []string{"nu", "--bind", "ctrl-g"}, want: "--bind is only supported for bash, zsh and fish"},
+	}
+	for _, tt := range tests {
+		if err := cmdInit(tt.args); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("cmdInit(%v) error = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+}
+
+func TestCmdInit_NoWrapBM(t *testing.T) {
+	bmWrappers := []string{"bm() {", "function bm\n", "function global:bm {", "def --env --wrapped bm ", "fn bm {", `aliases["bm"]`}
+	jWrappers := []string{"j() {", "function j\n", "function global:j {", "def --env --wrapped j ", "fn j {", `aliases["j"]`}
+	defines := func(out string, wrappers []string) bool {
+		for _, wrapper := range wrappers {
+			if strings.Contains(out, wrapper) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, shell := range []string{"bash", "fish", "pwsh", "nu", "elvish", "xonsh"} {
+		for _, args := range [][]string{{"--cmd", "j"}, {"--cmd", "j", "--no-wrap-bm"}} {
+			out, err := captureStdout(t, func() error { return cmdInit(append([]string{shell}, args...)) })
+			if err != nil {
+				t.Fatalf("cmdInit(%s %v) error = %v", shell, args, err)
+			}
+			// The wrapper and helpers are renamed, and bm itself is left alone.
+			if strings.Contains(out, "bmcd") || strings.Contains(out, "bmgo") || !strings.Contains(out, "jcd") || !strings.Contains(out, "jgo") {
+				t.Fatalf("cmdInit(%s %v) helpers not renamed:\n%s", shell, args, out)
+			}
+			if defines(out, bmWrappers) || !defines(out, jWrappers) {
+				t.Fatalf("cmdInit(%s %v) wrapper not renamed to j:\n%s", shell, args, out)
+			}
+		}
+
+		out, err := captureStdout(t, func() error { return cmdInit([]string{shell, "--no-wrap-bm"}) })
+		if err != nil {
+			t.Fatalf("cmdInit(%s --no-wrap-bm) error = %v", shell, err)
+		}
+		if defines(out, bmWrappers) || !strings.Contains(out, "bmcd") {
+			t.Fatalf("cmdInit(%s --no-wrap-bm) still defines the bm wrapper:\n%s", shell, out)
+		}
+	}
+}
+
+func TestResolveShellName(t *testing.T) {
+	for arg, want := range map[string]string{"PowerShell": "pwsh", "nushell": "nu", "Elvish": "elvish"} {
+		if got, err := resolveShellName([]string{arg}); err != nil || got != want {
+			t.Fatalf("resolveShellName(%q) = %q, %v", arg, got, err)
+		}
+	}
+	for shell, want := range map[string]string{"/usr/bin/pwsh": "pwsh", "/usr/local/bin/nu": "nu", "/opt/xonsh/bin/xonsh": "xonsh", "C:/pwsh/pwsh.exe": "pwsh"} {
+		t.Setenv("SHELL", shell)
+		if got, err := resolveShellName(nil); err != nil || got != want {
+			t.Fatalf("resolveShellName() with SHELL=%s = %q, %v", shell, got, err)
+		}
+	}
+	t.Setenv("SHELL", "/bin/tcsh")
+	if _, err := resolveShellName(nil); err == nil || !strings.Contains(err.Error(), "pass one of: bash, zsh, fish, pwsh, nu, elvish or xonsh") {
+		t.Fatalf("resolveShellName() with SHELL=tcsh error = %v", err)
+	}
+}
+
+// TestShellInit_Exec sources each init script in its shell, when installed,
+// with this test binary standing in for bm, and checks that bm go, bmgo and
+// bm find succeed and change the shell's directory.
+func TestShellInit_Exec(t *testing.T) {
+	tests := []struct {
+		shell string
+		args  []string
+		ext   string
+		// setup loads the integration; initPath holds the output of bm init.
+		setup func(initPath string) string
+		pwd   string
+		// orExit follows each jump to end the script when it fails. Nushell
+		// and Elvish already stop on a failing external command.
+		orExit string
+	}{
+		{shell: "bash", ext: "sh", setup: func(string) string { return `eval "$(bm init bash)"` }, pwd: "pwd", orExit: " || exit 1"},
+		{shell: "zsh", args: []string{"-f"}, ext: "zsh", setup: func(string) string { return `eval "$(bm init zsh)"` }, pwd: "pwd", orExit: " || exit 1"},
+		{shell: "fish", args: []string{"--no-config"}, ext: "fish", setup: func(string) string { return "bm init fish | source" }, pwd: "pwd", orExit: "; or exit 1"},
+		{shell: "pwsh", args: []string{"-NoProfile", "-NonInteractive", "-File"}, ext: "ps1", setup: func(string) string { return "bm init pwsh | Out-String | Invoke-Expression" }, pwd: "(Get-Location).Path", orExit: "; if (-not $?) { exit 1 }"},
+		// Nushell only sources files known when the script is parsed.
+		{shell: "nu", args: []string{"--no-config-file"}, ext: "nu", setup: func(p string) string { return "source '" + p + "'" }, pwd: "print $env.PWD"},
+		// edit: only exists in interactive sessions; the functions are in scope
+		// at the top level of the script anyway.
+		{shell: "elvish", args: []string{"-norc"}, ext: "elv", setup: func(p string) string {
+			data, _ := os.ReadFile(p)
+			var lines []string
+			for _, line := range strings.Split(string(data), "\n") {
+				if !strings.HasPrefix(line, "edit:") {
+					lines = append(lines, line)
+				}
+			}
+			return strings.Join(lines, "\n")
+		}, pwd: "echo $pwd"},
+		{shell: "xonsh", args: []string{"--no-rc"}, ext: "xsh", setup: func(string) string {
+			return "$RAISE_SUBPROC_ERROR = True\nexecx($(bm init xonsh), 'exec', __xonsh__.ctx, filename='bm')"
+		}, pwd: "print($PWD)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			shellPath, err := exec.LookPath(tt.shell)
+			if err != nil {
+				t.Skipf("%s is not installed", tt.shell)
+			}
+			root, proj, env := shellTestEnv(t)
+
+			initScript, err := captureStdout(t, func() error { return cmdInit([]string{tt.shell}) })
+			if err != nil {
+				t.Fatalf("cmdInit(%s) error = %v", tt.shell, err)
+			}
+			initPath := filepath.Join(root, "init."+tt.ext)
+			if err := os.WriteFile(initPath, []byte(initScript), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			script := []string{tt.setup(initPath)}
+			for _, jump := range []string{"bm go proj", "bmgo proj", "bm find --query proj"} {
+				script = append(script, "cd /", jump+tt.orExit, tt.pwd)
+			}
+			scriptPath := filepath.Join(root, "test."+tt.ext)
+			if err := os.WriteFile(scriptPath, []byte(strings.Join(script, "\n")+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			cmd := exec.Command(shellPath, append(tt.args, scriptPath)...)
+			cmd.Dir = root
+			cmd.Env = env
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("%s error = %v\n%s", tt.shell, err, out)
+			}
+			want := strings.Repeat(proj+"\n", 3)
+			if string(out) != want {
+				t.Fatalf("%s printed %q, want %q", tt.shell, out, want)
+			}
+		})
+	}
+}
+
+// shellTestEnv sets up a home with a "proj" bookmark pointing at proj and an
+// environment where bm runs this test binary.
+func shellTestEnv(t *testing.T) (root, proj string, env []string) {
+	t.Helper()
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable() error = %v", err)
+	}
+	root = t.TempDir()
+	bin := filepath.Join(root, "bin")
+	proj = filepath.Join(root, "my proj")
+	for _, dir := range []string{bin, proj} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(self, filepath.Join(bin, "bm")); err != nil {
+		t.Fatal(err)
+	}
+	storePath := filepath.Join(root, "config", "bm", "bookmarks.tsv")
+	if err := bookmarks.Save(storePath, []bookmarks.Bookmark{{Name: "proj", Path: proj}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	env = append(os.Environ(),
+		"BM_TEST_RUN_MAIN=1",
+		"PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"HOME="+root,
+		"XDG_CONFIG_HOME="+filepath.Join(root, "config"),
+		"XDG_STATE_HOME="+filepath.Join(root, "state"),
+	)
+	return root, proj, env
+}
+
+func TestShellInit_ExecPrefix(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+	root, proj, env := shellTestEnv(t)
+	script := `eval "$(bm init bash --cmd j)"
+jgo proj
+pwd
+cd /
+j go proj
+pwd
+type -t bm
+type -t j
+`
+	cmd := exec.Command(bash, "-c", script)
+	cmd.Dir = root
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash error = %v\n%s", err, out)
+	}
+	if want := proj + "\n" + proj + "\nfile\nfunction\n"; string(out) != want {
+		t.Fatalf("bash printed %q, want %q", out, want)
+	}
+}
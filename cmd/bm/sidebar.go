@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+// sidebarWidth is the outer width of the tag sidebar in the find view.
+const sidebarWidth = 26
+
+// tagSidebar lists every tag with its bookmark count; toggling tags filters
+// the find list to bookmarks carrying any (or, with matchAll, every) active
+// tag.
+type tagSidebar struct {
+	open     bool
+	focused  bool
+	cursor   int
+	tags     []string
+	counts   map[string]int
+	active   map[string]bool
+	matchAll bool
+}
+
+func newTagSidebar(entries []bookmarks.Bookmark, active []string) tagSidebar {
+	s := tagSidebar{active: map[string]bool{}, open: len(active) > 0}
+	for _, tag := range active {
+		s.active[tag] = true
+	}
+	s.setEntries(entries)
+	return s
+}
+
+// setEntries recounts the tags after the bookmarks changed. Active tags the
+// change removed from their last bookmark are dropped; other active tags no
+// bookmark carries, such as a mistyped --tag, stay listed with a count of 0
+// so the filter keeps applying until toggled off.
+func (s *tagSidebar) setEntries(entries []bookmarks.Bookmark) {
+	before := s.counts
+	s.tags, s.counts = tagCounts(entries)
+	for tag := range s.active {
+		switch {
+		case s.counts[tag] > 0:
+		case before[tag] > 0:
+			delete(s.active, tag)
+		default:
+			s.tags = append(s.tags, tag)
+		}
+	}
+	sort.Strings(s.tags)
+	s.cursor = min(s.cursor, max(0, len(s.tags)-1))
+}
+
+// activeTags returns the active tags, sorted.
+func (s tagSidebar) activeTags() []string {
+	var tags []string
+	for _, tag := range s.tags {
+		if s.active[tag] {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// filter keeps the entries matching the active tags.
+func (s tagSidebar) filter(entries []bookmarks.Bookmark) []bookmarks.Bookmark {
+	if s.matchAll {
+		return filterByAllTags(entries, s.activeTags())
+	}
+	return filterByAnyTag(entries, s.activeTags())
+}
+
+// mode describes how active tags combine.
+func (s tagSidebar) mode() string {
+	if s.matchAll {
+		return "all"
+	}
+	return "any"
+}
+
+// update handles a key while the sidebar is focused and reports whether the
+// filter changed. esc hands focus back to the list.
+func (s tagSidebar) update(msg tea.KeyMsg) (tagSidebar, bool) {
+	switch msg.String() {
+	case "esc":
+		s.focused = false
+	case "up", "k":
+		s.cursor = max(0, s.cursor-1)
+	case "down", "j":
+		s.cursor = min(max(0, len(s.tags)-1), s.cursor+1)
+	case " ", "enter":
+		if s.cursor < len(s.tags) {
+			tag := s.tags[s.cursor]
+			if s.active[tag] {
+				delete(s.active, tag)
+			} else {
+				s.active[tag] = true
+			}
+			return s, true
+		}
+	case "m":
+		s.matchAll = !s.matchAll
+		return s, len(s.active) > 1
+	case "x":
+		changed := len(s.active) > 0
+		clear(s.active)
+		return s, changed
+	}
+	return s, false
+}
+
+// View draws the sidebar in a box height rows tall, scrolled to keep the
+// cursor visible.
+func (s tagSidebar) View(height int) string {
+	border := ui.sidebarBorder
+	if s.focused {
+		border = border.BorderForeground(ui.focusBorder)
+	}
+	inner := sidebarWidth - border.GetHorizontalFrameSize()
+	rows := max(1, height-border.GetVerticalFrameSize())
+
+	lines := []string{ui.heading.Render("Tags") + ui.dim.Render(" ("+s.mode()+")")}
+	if len(s.tags) == 0 {
+		lines = append(lines, ui.dim.Render("no tags"))
+	}
+	for i := s.firstVisible(rows); i < len(s.tags) && len(lines) < rows; i++ {
+		tag := s.tags[i]
+		box := "[ ] "
+		if s.active[tag] {
+			box = "[x] "
+		}
+		count := strconv.Itoa(s.counts[tag])
+		name := ansi.Truncate(tag, max(1, inner-len(box)-len(count)-1), "…")
+		label := box + name
+		if s.focused && i == s.cursor {
+			label = ui.cursor.Render(label)
+		}
+		pad := strings.Repeat(" ", max(1, inner-len(box)-ansi.StringWidth(name)-len(count)))
+		lines = append(lines, label+pad+ui.dim.Render(count))
+	}
+	return border.
+		Width(sidebarWidth - border.GetHorizontalBorderSize()).
+		Height(rows).
+		Render(strings.Join(lines, "\n"))
+}
+
+// firstVisible returns the first tag shown when rows lines fit in the box,
+// one of them taken by the heading.
+func (s tagSidebar) firstVisible(rows int) int {
+	return max(0, min(s.cursor-(rows-1)/2, len(s.tags)-(rows-1)))
+}
+
+// tagAt returns the tag drawn on line y of a sidebar height rows tall.
+func (s tagSidebar) tagAt(y, height int) (int, bool) {
+	rows := max(1, height-ui.sidebarBorder.GetVerticalFrameSize())
+	// Skip the top border and the heading.
+	line := y - ui.sidebarBorder.GetBorderTopSize() - 1
+	if line < 0 || line >= rows-1 {
+		return 0, false
+	}
+	i := s.firstVisible(rows) + line
+	return i, i < len(s.tags)
+}
+
+// banner describes the active tag filter for when the sidebar is closed.
+func (s tagSidebar) banner() string {
+	tags := s.activeTags()
+	if len(tags) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("filters: %s (%s)", strings.Join(tags, ", "), s.mode())
+}
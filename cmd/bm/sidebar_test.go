@@ -0,0 +1,141 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+func sidebarEntries() []bookmarks.Bookmark {
+	return []bookmarks.Bookmark{
+		{Name: "api", Path: "/src/api", Tags: []string{"go", "work"}},
+		{Name: "web", Path: "/src/web", Tags: []string{"js", "work"}},
+		{Name: "dots", Path: "/src/dots", Tags: []string{"go"}},
+		{Name: "tmp", Path: "/tmp"},
+	}
+}
+
+func names(entries []bookmarks.Bookmark) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Name)
+	}
+	return out
+}
+
+func TestTagSidebar_Filter(t *testing.T) {
+	s := newTagSidebar(sidebarEntries(), []string{"go", "work"})
+	if !s.open || !reflect.DeepEqual(s.tags, []string{"go", "js", "work"}) || s.counts["work"] != 2 {
+		t.Fatalf("sidebar = %#v", s)
+	}
+	if got := names(s.filter(sidebarEntries())); !reflect.DeepEqual(got, []string{"api", "web", "dots"}) {
+		t.Fatalf("any filter = %v", got)
+	}
+	s, changed := s.update(keyMsg("m"))
+	if !changed || s.mode() != "all" {
+		t.Fatalf("m did not switch to all")
+	}
+	if got := names(s.filter(sidebarEntries())); !reflect.DeepEqual(got, []string{"api"}) {
+		t.Fatalf("all filter = %v", got)
+	}
+	s, changed = s.update(keyMsg("x"))
+	if !changed || len(s.filter(sidebarEntries())) != 4 {
+		t.Fatalf("x did not clear the filter")
+	}
+
+	// Tags no bookmark carries any more are dropped.
+	s.active["js"] = true
+	s.setEntries(sidebarEntries()[:1])
+	if len(s.activeTags()) != 0 || !reflect.DeepEqual(s.tags, []string{"go", "work"}) {
+		t.Fatalf("after setEntries = %#v", s)
+	}
+}
+
+func TestTagSidebar_KeepsUnknownLaunchTag(t *testing.T) {
+	s := newTagSidebar(sidebarEntries(), []string{"typo"})
+	if got := s.filter(sidebarEntries()); len(got) != 0 {
+		t.Fatalf("filter = %v, want no bookmarks", names(got))
+	}
+	if s.banner() != "filters: typo (any)" || !reflect.DeepEqual(s.tags, []string{"go", "js", "typo", "work"}) {
+		t.Fatalf("sidebar = %#v", s)
+	}
+	s.setEntries(sidebarEntries()[:1])
+	if !reflect.DeepEqual(s.activeTags(), []string{"typo"}) {
+		t.Fatalf("after setEntries active = %v", s.activeTags())
+	}
+	if view := ansi.Strip(s.View(8)); !strings.Contains(view, "[x] typo") {
+		t.Fatalf("view missing typo:\n%s", view)
+	}
+
+	m := newFindModel("", sidebarEntries(), "bm find", []string{"typo"})
+	if got := m.entries(); len(got) != 0 {
+		t.Fatalf("bm find --tag typo lists %v", names(got))
+	}
+}
+
+func TestTagSidebar_View(t *testing.T) {
+	s := newTagSidebar(sidebarEntries(), []string{"js"})
+	s.focused = true
+	view := ansi.Strip(s.View(8))
+	for _, want := range []string{"Tags (any)", "[ ] go", "[x] js", "[ ] work"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("view missing %q:\n%s", want, view)
+		}
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if w := ansi.StringWidth(line); w != sidebarWidth {
+			t.Fatalf("line is %d wide, want %d: %q", w, sidebarWidth, line)
+		}
+	}
+}
+
+func TestFindModel_TagSidebar(t *testing.T) {
+	var m tea.Model = newFindModel("", sidebarEntries(), "bm find", nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+	if got := names(m.(findModel).entries()); len(got) != 4 {
+		t.Fatalf("initial list = %v", got)
+	}
+
+	// tab opens and focuses the sidebar; toggling tags filters live.
+	m = press(m, "tab", " ")
+	if got := names(m.(findModel).entries()); !reflect.DeepEqual(got, []string{"api", "dots"}) {
+		t.Fatalf("go filter = %v", got)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = press(m, " ")
+	if got := names(m.(findModel).entries()); !reflect.DeepEqual(got, []string{"api", "web", "dots"}) {
+		t.Fatalf("go or work = %v", got)
+	}
+	m = press(m, "m")
+	if got := names(m.(findModel).entries()); !reflect.DeepEqual(got, []string{"api"}) {
+		t.Fatalf("go and work = %v", got)
+	}
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "Tags (all)") || !strings.Contains(view, "[x] work") {
+		t.Fatalf("view:\n%s", view)
+	}
+
+	// Closing the sidebar keeps the filter and shows it in a banner.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = press(m, "T")
+	view = ansi.Strip(m.View())
+	if strings.Contains(view, "Tags (all)") || !strings.Contains(view, "filters: go, work (all)") {
+		t.Fatalf("closed view:\n%s", view)
+	}
+	if m.(findModel).sidebar.focused {
+		t.Fatalf("sidebar still focused")
+	}
+}
+
+func TestFilterByAllTags(t *testing.T) {
+	got := names(filterByAllTags(sidebarEntries(), []string{"GO", "work"}))
+	if !reflect.DeepEqual(got, []string{"api"}) {
+		t.Fatalf("filterByAllTags() = %v", got)
+	}
+}
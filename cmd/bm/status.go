@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+	"github.com/navio/bookmarks/internal/gitinfo"
+)
+
+const (
+	gitTimeout = 2 * time.Second
+	gitWorkers = 8
+)
+
+// gitCache is shared by the TUIs so re-rendering never re-runs git for
+// recently inspected repositories.
+var gitCache = gitinfo.NewCache(30 * time.Second)
+
+func cmdStatus(storePath string, args []string) error {
+	positionals, err := parseArgs(args, map[string]bool{"--tag": true, "--json": false, "--timeout": true})
+	if err != nil {
+		return err
+	}
+	if len(positionals.args) != 0 {
+		return errors.New("usage: bm status [--tag x] [--json] [--timeout 2s]")
+	}
+	_, jsonOutput := positionals.flags["--json"]
+	timeout := gitTimeout
+	if v, ok := positionals.flags["--timeout"]; ok {
+		timeout, err = time.ParseDuration(v)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("--timeout expects a positive duration, got %q", v)
+		}
+	}
+
+	entries, err := bookmarks.Load(storePath)
+	if err != nil {
+		return err
+	}
+	entries = filterByAnyTag(resolveRepoPaths(entries), parseTagFilters(positionals.flags))
+	dirs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		dirs = append(dirs, e.Path)
+	}
+	results := gitinfo.InspectAll(context.Background(), dirs, timeout, gitWorkers)
+
+	now := time.Now()
+	if jsonOutput {
+		payload := make([]map[string]any, 0, len(results))
+		for i, r := range results {
+			if errors.Is(r.Err, gitinfo.ErrNotRepo) {
+				continue
+			}
+			item := map[string]any{
+				"name": entries[i].Name,
+				"path": entries[i].Path,
+			}
+			if r.Err != nil {
+				item["error"] = r.Err.Error()
+			} else {
+				item["branch"] = r.Status.Branch
+				item["upstream"] = r.Status.HasUpstream
+				item["ahead"] = r.Status.Ahead
+				item["behind"] = r.Status.Behind
+				item["dirty"] = r.Status.Dirty
+				if !r.Status.LastCommit.IsZero() {
+					item["last_commit"] = r.Status.LastCommit.UTC().Format(time.RFC3339)
+				}
+			}
+			payload = append(payload, item)
+		}
+		encoded, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(encoded))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tBRANCH\tAHEAD/BEHIND\tDIRTY\tLAST COMMIT\tPATH")
+	for i, r := range results {
+		if errors.Is(r.Err, gitinfo.ErrNotRepo) {
+			continue
+		}
+		if r.Err != nil {
+			fmt.Fprintf(tw, "%s\t%s\t\t\t\t%s\n", entries[i].Name, "error: "+r.Err.Error(), entries[i].Path)
+			continue
+		}
+		st := r.Status
+		ab := "-"
+		if st.HasUpstream {
+			ab = fmt.Sprintf("+%d/-%d", st.Ahead, st.Behind)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			entries[i].Name, st.Branch, ab, st.Dirty, formatAge(st.LastCommit, now), entries[i].Path)
+	}
+	return tw.Flush()
+}
+
+// formatGitCell renders a compact status for a table cell, e.g. "main ↑1↓2 ~3".
+func formatGitCell(r gitinfo.Result) string {
+	if errors.Is(r.Err, gitinfo.ErrNotRepo) {
+		return ""
+	}
+	if r.Err != nil {
+		return "?"
+	}
+	st := r.Status
+	var b strings.Builder
+	b.WriteString(st.Branch)
+	if st.Ahead > 0 || st.Behind > 0 {
+		b.WriteString(" ")
+		if st.Ahead > 0 {
+			b.WriteString("↑" + strconv.Itoa(st.Ahead))
+		}
+		if st.Behind > 0 {
+			b.WriteString("↓" + strconv.Itoa(st.Behind))
+		}
+	}
+	if st.Dirty > 0 {
+		b.WriteString(" ~" + strconv.Itoa(st.Dirty))
+	}
+	return b.String()
+}
+
+// formatAge renders the time since t in its largest unit, e.g. "3d" or "5h".
+func formatAge(t, now time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return strconv.Itoa(int(d/time.Minute)) + "m"
+	case d < 24*time.Hour:
+		return strconv.Itoa(int(d/time.Hour)) + "h"
+	case d < 60*24*time.Hour:
+		return strconv.Itoa(int(d/(24*time.Hour))) + "d"
+	default:
+		return strconv.Itoa(int(d/(30*24*time.Hour))) + "mo"
+	}
+}
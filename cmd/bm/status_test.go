@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/navio/bookmarks/internal/gitinfo"
+)
+
+func TestFormatAge(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Time{}, "-"},
+		{now.Add(-30 * time.Second), "now"},
+		{now.Add(-5 * time.Minute), "5m"},
+		{now.Add(-3 * time.Hour), "3h"},
+		{now.Add(-50 * time.Hour), "2d"},
+		{now.Add(-90 * 24 * time.Hour), "3mo"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.t, now); got != tt.want {
+			t.Fatalf("formatAge(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestFormatGitCell(t *testing.T) {
+	got := formatGitCell(gitinfo.Result{Status: gitinfo.Status{Branch: "main", Ahead: 1, Behind: 2, Dirty: 3}})
+	if want := "main ↑1↓2 ~3"; got != want {
+		t.Fatalf("formatGitCell() = %q, want %q", got, want)
+	}
+	if got := formatGitCell(gitinfo.Result{Err: gitinfo.ErrNotRepo}); got != "" {
+		t.Fatalf("formatGitCell(not repo) = %q, want empty", got)
+	}
+	if got := formatGitCell(gitinfo.Result{Err: errors.New("timed out")}); got != "?" {
+		t.Fatalf("formatGitCell(error) = %q, want ?", got)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+func cmdSuggest(storePath string, args []string) error {
+	positionals, err := parseArgs(args, map[string]bool{"--history": true, "--limit": true, "--json": false, "--theme": true})
+	if err != nil {
+		return err
+	}
+	if len(positionals.args) != 0 {
+		return errors.New("usage: bm suggest [--history file] [--limit N] [--json] [--theme name]")
+	}
+	limit := 20
+	if v, ok := positionals.flags["--limit"]; ok {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return fmt.Errorf("--limit expects a positive number, got %q", v)
+		}
+	}
+	_, jsonOutput := positionals.flags["--json"]
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	files := historyFiles(home)
+	if v, ok := positionals.flags["--history"]; ok {
+		files = []string{v}
+	}
+
+	counts := map[string]int{}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		r := bufio.NewReader(f)
+		commands, err := bookmarks.ReadHistory(r, bookmarks.HistoryFormat(file, r))
+		_ = f.Close()
+		if err != nil {
+			return fmt.Errorf("read %s: %w", file, err)
+		}
+		for dir, n := range bookmarks.CdTargets(commands, home) {
+			counts[dir] += n
+		}
+	}
+
+	// Directories logged by `bm record` count alongside history.
+	hotPath, err := bookmarks.DefaultHotDirsPath()
+	if err != nil {
+		return err
+	}
+	hot, err := bookmarks.LoadVisits(hotPath)
+	if err != nil {
+		return err
+	}
+	for dir, v := range hot {
+		counts[dir] += v.Count
+	}
+
+	entries, err := bookmarks.Load(storePath)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	themeName := themeFlag(positionals.flags, cfg)
+	if err := checkTheme(themeName); err != nil {
+		return err
+	}
+	suggestions := bookmarks.Suggest(counts, entries, home, limit)
+	for i := range suggestions {
+		suggestions[i].Tags = bookmarks.AddTags(suggestions[i].Tags, bookmarks.ApplyRules(cfg.Rules, suggestions[i].Path))
+	}
+
+	if jsonOutput {
+		payload := make([]map[string]any, 0, len(suggestions))
+		for _, s := range suggestions {
+			payload = append(payload, map[string]any{
+				"name":  s.Name,
+				"path":  s.Path,
+				"tags":  s.Tags,
+				"count": s.Count,
+			})
+		}
+		encoded, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(encoded))
+		return nil
+	}
+	if len(suggestions) == 0 {
+		fmt.Fprintln(os.Stderr, "no suggestions")
+		return nil
+	}
+
+	items := make([]pickItem, 0, len(suggestions))
+	for _, s := range suggestions {
+		desc := fmt.Sprintf("%s  (%d visits)", s.Path, s.Count)
+		if len(s.Tags) > 0 {
+			desc += "  [" + strings.Join(s.Tags, ",") + "]"
+		}
+		items = append(items, pickItem{key: s.Path, title: s.Name, desc: desc})
+	}
+	if err := setupTheme(themeName); err != nil {
+		return err
+	}
+	keys, err := runPickTUI(items, "bm suggest", false)
+	if err != nil {
+		return err
+	}
+	candidates := make([]bookmarks.Candidate, 0, len(suggestions))
+	for _, s := range suggestions {
+		candidates = append(candidates, bookmarks.Candidate{Name: s.Name, Path: s.Path, Tags: s.Tags})
+	}
+	chosen := selectCandidates(candidates, keys)
+	if len(chosen) == 0 {
+		return nil
+	}
+	return addCandidates(storePath, entries, chosen, "suggest")
+}
+
+// historyFiles lists the shell history files read by default: $HISTFILE and
+// the standard bash, zsh and fish locations.
+func historyFiles(home string) []string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	candidates := []string{
+		os.Getenv("HISTFILE"),
+		filepath.Join(home, ".bash_history"),
+		filepath.Join(home, ".zsh_history"),
+		filepath.Join(dataHome, "fish", "fish_history"),
+	}
+	var files []string
+	seen := map[string]bool{}
+	for _, f := range candidates {
+		if f == "" || seen[f] {
+			continue
+		}
+		seen[f] = true
+		files = append(files, f)
+	}
+	return files
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCmdSuggest_JSONFromHistory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	api := filepath.Join(home, "src", "api")
+	web := filepath.Join(home, "src", "web")
+	for _, dir := range []string{api, web} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	history := filepath.Join(home, ".zsh_history")
+	content := ": 1700000000:0;cd ~/src/api\n: 1700000001:0;cd ../web\n: 1700000002:0;cd ~/src/api\n"
+	if err := os.WriteFile(history, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	storePath := filepath.Join(home, "bm.tsv")
+	if err := cmdAdd(storePath, []string{"web", web}); err != nil {
+		t.Fatalf("cmdAdd() error = %v", err)
+	}
+
+	out, err := captureStdout(t, func() error {
+		return cmdSuggest(storePath, []string{"--history", history, "--json"})
+	})
+	if err != nil {
+		t.Fatalf("cmdSuggest() error = %v", err)
+	}
+	var got []struct {
+		Name  string `json:"name"`
+		Path  string `json:"path"`
+		Count int    `json:"count"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", out, err)
+	}
+	if len(got) != 1 || got[0].Name != "api" || got[0].Path != api || got[0].Count != 2 {
+		t.Fatalf("suggestions = %#v", got)
+	}
+}
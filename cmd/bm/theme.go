@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// theme is the palette the TUIs draw with. A nil color leaves the terminal's
+// own color in place.
+type theme struct {
+	name string
+	// text is body text, accent headings and the highlighted item, dim
+	// borders and secondary text.
+	text, accent, dim lipgloss.TerminalColor
+	// selectFg and selectBg color the highlighted table row; reverse swaps
+	// the terminal's colors instead.
+	selectFg, selectBg lipgloss.TerminalColor
+	reverse            bool
+	titleFg, titleBg   lipgloss.TerminalColor
+	ok, err            lipgloss.TerminalColor
+}
+
+// themes are the named themes, in the order help lists them.
+var themes = []theme{
+	{
+		name: "dark", text: lipgloss.Color("252"), accent: lipgloss.Color("212"), dim: lipgloss.Color("241"),
+		selectFg: lipgloss.Color("229"), selectBg: lipgloss.Color("57"),
+		titleFg: lipgloss.Color("230"), titleBg: lipgloss.Color("62"),
+		ok: lipgloss.Color("10"), err: lipgloss.Color("9"),
+	},
+	{
+		name: "light", text: lipgloss.Color("235"), accent: lipgloss.Color("127"), dim: lipgloss.Color("242"),
+		selectFg: lipgloss.Color("231"), selectBg: lipgloss.Color("25"),
+		titleFg: lipgloss.Color("231"), titleBg: lipgloss.Color("25"),
+		ok: lipgloss.Color("28"), err: lipgloss.Color("160"),
+	},
+	// high-contrast keeps text in the terminal's foreground, never dims it,
+	// and tells states apart by blue and orange rather than green and red.
+	{
+		name:    "high-contrast",
+		accent:  lipgloss.AdaptiveColor{Light: "18", Dark: "226"},
+		ok:      lipgloss.AdaptiveColor{Light: "19", Dark: "45"},
+		err:     lipgloss.AdaptiveColor{Light: "130", Dark: "214"},
+		reverse: true,
+	},
+	{name: "no-color", reverse: true},
+}
+
+// themeNames lists the names --theme accepts.
+func themeNames() []string {
+	names := []string{"auto"}
+	for _, t := range themes {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+// checkTheme reports an error unless name is a theme --theme accepts ("" is
+// auto).
+func checkTheme(name string) error {
+	if name == "" || slices.Contains(themeNames(), name) {
+		return nil
+	}
+	return fmt.Errorf("unknown theme: %s (expected %s)", name, strings.Join(themeNames(), ", "))
+}
+
+// resolveTheme returns the theme called name. "auto" (or "") picks no-color
+// when NO_COLOR is set and otherwise dark or light to match the background of
+// the terminal r draws on.
+func resolveTheme(r *lipgloss.Renderer, name string) (theme, error) {
+	if err := checkTheme(name); err != nil {
+		return theme{}, err
+	}
+	switch name {
+	case "", "auto":
+		switch {
+		case os.Getenv("NO_COLOR") != "":
+			name = "no-color"
+		case r.HasDarkBackground():
+			name = "dark"
+		default:
+			name = "light"
+		}
+	}
+	i := slices.IndexFunc(themes, func(t theme) bool { return t.name == name })
+	return themes[i], nil
+}
+
+// setupTheme points lipgloss at stderr, where the TUIs draw (stdout is
+// usually captured by the shell wrapper), and switches to the named theme.
+func setupTheme(name string) error {
+	r := lipgloss.NewRenderer(os.Stderr)
+	t, err := resolveTheme(r, name)
+	if err != nil {
+		return err
+	}
+	if t.name != "no-color" && os.Getenv("NO_COLOR") != "" {
+		// A theme chosen by flag or config overrides NO_COLOR, which the
+		// renderer honors on its own.
+		r.SetColorProfile(termenv.NewOutput(os.Stderr).ColorProfile())
+	}
+	lipgloss.SetDefaultRenderer(r)
+	ui = newUIStyles(t)
+	return nil
+}
+
+// uiStyles are the styles derived from a theme.
+type uiStyles struct {
+	title, heading, dim, footer, banner, prompt lipgloss.Style
+	status, error                               lipgloss.Style
+	cursor                                      lipgloss.Style
+	previewBorder, sidebarBorder, helpBorder    lipgloss.Style
+	// focusBorder colors the border of the focused pane.
+	focusBorder lipgloss.TerminalColor
+	list        list.Styles
+	item        list.DefaultItemStyles
+	help        help.Styles
+	table       table.Styles
+}
+
+// ui holds the current styles; setupTheme replaces them.
+var ui = newUIStyles(themes[0])
+
+// fg sets the foreground to c, or clears it when c is nil so the defaults of
+// the bubbles styles do not leak through.
+func fg(s lipgloss.Style, c lipgloss.TerminalColor) lipgloss.Style {
+	if c == nil {
+		return s.UnsetForeground()
+	}
+	return s.Foreground(c)
+}
+
+func border(s lipgloss.Style, c lipgloss.TerminalColor) lipgloss.Style {
+	if c == nil {
+		return s.UnsetBorderForeground()
+	}
+	return s.BorderForeground(c)
+}
+
+func newUIStyles(t theme) uiStyles {
+	var s uiStyles
+	accent := fg(lipgloss.NewStyle().Bold(true), t.accent)
+	s.heading = accent
+	s.prompt = accent
+	s.banner = accent
+	s.cursor = accent
+	s.dim = fg(lipgloss.NewStyle(), t.dim)
+	s.footer = s.dim
+	s.status = fg(lipgloss.NewStyle(), t.ok)
+	s.error = fg(lipgloss.NewStyle(), t.err)
+	if t.reverse {
+		s.title = lipgloss.NewStyle().Reverse(true).Bold(true)
+	} else {
+		s.title = lipgloss.NewStyle().Foreground(t.titleFg).Background(t.titleBg)
+	}
+
+	box := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+	s.previewBorder = border(box, t.dim)
+	s.sidebarBorder = border(box, t.dim)
+	s.helpBorder = border(box.Padding(0, 2), t.accent)
+	s.focusBorder = t.accent
+	if s.focusBorder == nil {
+		s.focusBorder = lipgloss.NoColor{}
+	}
+
+	s.list = list.DefaultStyles()
+	s.list.Title = s.title.Padding(0, 1)
+	s.list.FilterPrompt = fg(lipgloss.NewStyle(), t.accent)
+	s.list.FilterCursor = fg(lipgloss.NewStyle(), t.accent)
+	s.list.StatusBar = fg(lipgloss.NewStyle(), t.dim).Padding(0, 0, 1, 2)
+	s.list.StatusEmpty = s.dim
+	s.list.StatusBarActiveFilter = fg(lipgloss.NewStyle(), t.text)
+	s.list.StatusBarFilterCount = s.dim
+	s.list.NoItems = s.dim
+	s.list.ArabicPagination = s.dim
+	s.list.ActivePaginationDot = fg(lipgloss.NewStyle(), t.text).SetString("•")
+	s.list.InactivePaginationDot = s.dim.SetString("•")
+	s.list.DividerDot = s.dim.SetString(" • ")
+	s.list.Spinner = s.dim
+
+	s.item = list.NewDefaultItemStyles()
+	s.item.NormalTitle = fg(s.item.NormalTitle, t.text).Bold(true)
+	s.item.NormalDesc = fg(s.item.NormalTitle.UnsetForeground().Bold(false), t.dim)
+	s.item.SelectedTitle = fg(border(s.item.SelectedTitle.UnsetForeground().UnsetBorderForeground(), t.accent), t.accent).Bold(true)
+	s.item.SelectedDesc = fg(s.item.SelectedTitle.UnsetForeground().Bold(false), t.accent)
+	s.item.DimmedTitle = fg(s.item.DimmedTitle.UnsetForeground(), t.dim).Bold(true)
+	s.item.DimmedDesc = fg(s.item.DimmedDesc.UnsetForeground(), t.dim)
+	if t.dim == nil {
+		// Without a dim color, dimmed items would look like the rest.
+		s.item.DimmedTitle = s.item.DimmedTitle.Faint(true)
+		s.item.DimmedDesc = s.item.DimmedDesc.Faint(true)
+	}
+
+	s.help = help.New().Styles
+	if t.dim == nil {
+		for _, st := range []*lipgloss.Style{
+			&s.help.Ellipsis, &s.help.ShortKey, &s.help.ShortDesc, &s.help.ShortSeparator,
+			&s.help.FullKey, &s.help.FullDesc, &s.help.FullSeparator,
+		} {
+			*st = st.UnsetForeground()
+		}
+	}
+
+	s.table = table.DefaultStyles()
+	s.table.Header = border(s.table.Header, t.dim)
+	if t.reverse {
+		s.table.Selected = lipgloss.NewStyle().Reverse(true)
+	} else {
+		s.table.Selected = lipgloss.NewStyle().Foreground(t.selectFg).Background(t.selectBg)
+	}
+	return s
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+func TestResolveTheme(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	r := lipgloss.NewRenderer(io.Discard)
+	for _, name := range []string{"dark", "light", "high-contrast", "no-color"} {
+		got, err := resolveTheme(r, name)
+		if err != nil || got.name != name {
+			t.Fatalf("resolveTheme(%q) = %q, %v", name, got.name, err)
+		}
+	}
+	// auto asks the renderer the TUIs draw with, not stdout.
+	for dark, want := range map[bool]string{true: "dark", false: "light"} {
+		r.SetHasDarkBackground(dark)
+		if got, err := resolveTheme(r, "auto"); err != nil || got.name != want {
+			t.Fatalf("resolveTheme(auto) on a dark=%v background = %q, %v", dark, got.name, err)
+		}
+	}
+	if _, err := resolveTheme(r, "solarized"); err == nil || !strings.Contains(err.Error(), "unknown theme: solarized (expected auto, dark") {
+		t.Fatalf("resolveTheme(solarized) error = %v", err)
+	}
+
+	// NO_COLOR only replaces the automatic choice.
+	t.Setenv("NO_COLOR", "1")
+	for name, want := range map[string]string{"": "no-color", "auto": "no-color", "light": "light"} {
+		if got, _ := resolveTheme(r, name); got.name != want {
+			t.Fatalf("resolveTheme(%q) with NO_COLOR = %q, want %q", name, got.name, want)
+		}
+	}
+}
+
+func TestNewUIStyles(t *testing.T) {
+	r := lipgloss.NewRenderer(io.Discard)
+	dark, _ := resolveTheme(r, "dark")
+	s := newUIStyles(dark)
+	if s.table.Selected.GetBackground() != lipgloss.Color("57") || s.heading.GetForeground() != lipgloss.Color("212") {
+		t.Fatalf("dark styles = %#v", s.table.Selected)
+	}
+
+	// Without colors the highlighted row and title fall back to reverse video
+	// and dimmed items to faint text, so they stay distinguishable.
+	plain, _ := resolveTheme(r, "no-color")
+	s = newUIStyles(plain)
+	if !s.table.Selected.GetReverse() || !s.list.Title.GetReverse() || !s.item.DimmedTitle.GetFaint() {
+		t.Fatalf("no-color styles lost their emphasis")
+	}
+	for name, style := range map[string]lipgloss.Style{
+		"heading": s.heading, "dim": s.dim, "error": s.error, "selected title": s.item.SelectedTitle,
+		"item title": s.item.NormalTitle, "active filter": s.list.StatusBarActiveFilter, "help key": s.help.ShortKey,
+	} {
+		if _, ok := style.GetForeground().(lipgloss.NoColor); !ok {
+			t.Fatalf("no-color %s style has foreground %v", name, style.GetForeground())
+		}
+	}
+	if _, ok := s.table.Header.GetBorderBottomForeground().(lipgloss.NoColor); !ok {
+		t.Fatalf("no-color table header border = %v", s.table.Header.GetBorderBottomForeground())
+	}
+}
+
+func TestCmdFind_UnknownTheme(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "bm.tsv")
+	if err := bookmarks.Save(storePath, promptEntries()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := cmdFind(storePath, []string{"--theme", "neon", "--query", "api"}); err == nil || !strings.Contains(err.Error(), "unknown theme: neon") {
+		t.Fatalf("cmdFind(--theme neon) error = %v", err)
+	}
+	if err := cmdTable(storePath, []string{"--theme", "neon"}); err == nil || !strings.Contains(err.Error(), "unknown theme: neon") {
+		t.Fatalf("cmdTable(--theme neon) error = %v", err)
+	}
+	if err := cmdScan(storePath, []string{t.TempDir(), "--theme", "neon"}); err == nil || !strings.Contains(err.Error(), "unknown theme: neon") {
+		t.Fatalf("cmdScan(--theme neon) error = %v", err)
+	}
+	if err := cmdSuggest(storePath, []string{"--history", filepath.Join(t.TempDir(), "none"), "--theme", "neon"}); err == nil || !strings.Contains(err.Error(), "unknown theme: neon") {
+		t.Fatalf("cmdSuggest(--theme neon) error = %v", err)
+	}
+
+	writeConfig(t, "theme = High-Contrast\n")
+	out, err := captureStdout(t, func() error {
+		return cmdFind(storePath, []string{"--query", "api"})
+	})
+	if err != nil || out != "bm go 'api'\n" {
+		t.Fatalf("cmdFind() with theme config = %q, %v", out, err)
+	}
+}
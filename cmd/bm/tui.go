@@ -1,28 +1,42 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
-	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 
 	"github.com/navio/bookmarks/internal/bookmarks"
+	"github.com/navio/bookmarks/internal/gitinfo"
 )
 
 type bookmarkItem struct {
 	b bookmarks.Bookmark
 }
 
-func (i bookmarkItem) Title() string       { return i.b.Name }
-func (i bookmarkItem) Description() string { return i.b.Path }
+func (i bookmarkItem) Title() string { return i.b.Name }
+
+// Description shows the bookmark description when set, otherwise its path.
+func (i bookmarkItem) Description() string {
+	if i.b.Description != "" {
+		return i.b.Description
+	}
+	return i.b.Path
+}
+
 func (i bookmarkItem) FilterValue() string {
-	return i.b.Name + " " + i.b.Path + " " + strings.Join(i.b.Tags, ",")
+	return i.b.Name + " " + i.b.Path + " " + strings.Join(i.b.Tags, ",") + " " + i.b.Description + " " + i.b.Notes
 }
 
 // ----------------
@@ -30,127 +44,845 @@ func (i bookmarkItem) FilterValue() string {
 // ----------------
 
 type findModel struct {
-	list     list.Model
-	selected string
-	tags     []string
+	list      list.Model
+	selected  []string
+	storePath string
+	prompt    actionPrompt
+	// pool holds every bookmark the picker was opened with; the list shows
+	// those matching the sidebar's active tags.
+	pool    []bookmarks.Bookmark
+	sidebar tagSidebar
+	// multi returns every marked bookmark on enter; marked is shared with the
+	// list delegate and keyed by name.
+	multi  bool
+	marked map[string]bool
+	// preview toggles the right-hand pane; previews caches loaded panes by
+	// path (nil while loading).
+	preview       bool
+	previews      map[string]*previewData
+	width, height int
+	keys          keyMap
+	clipboard     clipboardWriter
+	showHelp      bool
+	// lastClick is the item last clicked and when, to detect double-clicks.
+	lastClick   int
+	lastClickAt time.Time
+	// embedded leaves the footer to the dashboard the view is drawn in.
+	embedded bool
 }
 
-func newFindModel(items []list.Item, title string, tags []string) findModel {
+// minPreviewWidth is the narrowest terminal that shows the preview pane.
+const minPreviewWidth = 80
+
+func newFindModel(storePath string, entries []bookmarks.Bookmark, title string, tags []string) findModel {
 	delegate := list.NewDefaultDelegate()
-	delegate.Styles.NormalTitle = delegate.Styles.NormalTitle.Bold(true)
-	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.Bold(true)
-	delegate.Styles.DimmedTitle = delegate.Styles.DimmedTitle.Bold(true)
+	delegate.Styles = ui.item
+	marked := map[string]bool{}
 
-	lm := list.New(items, delegate, 0, 0)
+	sidebar := newTagSidebar(entries, tags)
+	lm := list.New(bookmarkListItems(sidebar.filter(entries)), markDelegate{DefaultDelegate: delegate, marked: marked}, 0, 0)
 	lm.Title = title
+	lm.Styles = ui.list
+	lm.Help.Styles = ui.help
 	lm.SetShowStatusBar(true)
 	lm.SetFilteringEnabled(true)
-	lm.KeyMap.Quit.SetEnabled(true)
-	return findModel{list: lm, tags: tags}
+	// The quit action and "?" help overlay replace the list's own bindings.
+	lm.KeyMap.Quit.SetKeys("esc")
+	lm.KeyMap.Quit.SetHelp("esc", "quit")
+	lm.KeyMap.ShowFullHelp.SetEnabled(false)
+	lm.KeyMap.CloseFullHelp.SetEnabled(false)
+	m := findModel{
+		list:      lm,
+		pool:      entries,
+		sidebar:   sidebar,
+		storePath: storePath,
+		marked:    marked,
+		preview:   true,
+		previews:  map[string]*previewData{},
+		clipboard: newClipboardWriter("auto"),
+		lastClick: -1,
+	}
+	m.setKeyMap(defaultKeyMap())
+	return m
+}
+
+// setKeyMap installs keys, freeing the list's paging keys they take.
+func (m *findModel) setKeyMap(keys keyMap) {
+	m.keys = keys
+	lk := list.DefaultKeyMap()
+	m.list.KeyMap.PrevPage, m.list.KeyMap.NextPage = lk.PrevPage, lk.NextPage
+	keys.unbind(&m.list.KeyMap.PrevPage)
+	keys.unbind(&m.list.KeyMap.NextPage)
+}
+
+// findActions are the key actions the find view offers.
+var findActions = []string{"jump", "mark", "copy", "preview", "sidebar", "toggle-sidebar", "rename", "tags", "delete", "add", "mark-all", "help", "quit"}
+
+// markDelegate renders bookmarks with a check mark when marked.
+type markDelegate struct {
+	list.DefaultDelegate
+	marked map[string]bool
+}
+
+func (d markDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if it, ok := item.(bookmarkItem); ok && d.marked[it.b.Name] {
+		it.b.Name = "✓ " + it.b.Name
+		item = it
+	}
+	d.DefaultDelegate.Render(w, m, index, item)
+}
+
+// targets returns the names an action applies to: the marked bookmarks in
+// list order, or the highlighted one when none is marked.
+func (m findModel) targets() []string {
+	var names []string
+	for _, item := range m.list.Items() {
+		if name := item.(bookmarkItem).b.Name; m.marked[name] {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		if it, ok := m.list.SelectedItem().(bookmarkItem); ok {
+			names = []string{it.b.Name}
+		}
+	}
+	return names
 }
 
-func (m findModel) Init() tea.Cmd { return nil }
+// toggleAllVisible marks every visible bookmark, or unmarks them when all
+// are already marked.
+func toggleAllVisible(marked map[string]bool, names []string) {
+	all := true
+	for _, name := range names {
+		all = all && marked[name]
+	}
+	for _, name := range names {
+		if all {
+			delete(marked, name)
+		} else {
+			marked[name] = true
+		}
+	}
+}
+
+func bookmarkListItems(entries []bookmarks.Bookmark) []list.Item {
+	items := make([]list.Item, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, bookmarkItem{b: e})
+	}
+	return items
+}
+
+// applyTagFilter shows the pool bookmarks matching the active tags, keeping
+// the highlighted bookmark when it still matches.
+func (m *findModel) applyTagFilter(highlight string) tea.Cmd {
+	cmd := m.list.SetItems(bookmarkListItems(m.sidebar.filter(m.pool)))
+	for i, item := range m.list.VisibleItems() {
+		if item.(bookmarkItem).b.Name == highlight {
+			m.list.Select(i)
+		}
+	}
+	m.resize()
+	return cmd
+}
+
+// highlighted returns the name of the highlighted bookmark, if any.
+func (m findModel) highlighted() string {
+	if it, ok := m.list.SelectedItem().(bookmarkItem); ok {
+		return it.b.Name
+	}
+	return ""
+}
+
+// entries returns the bookmarks in the list, in list order.
+func (m findModel) entries() []bookmarks.Bookmark {
+	entries := make([]bookmarks.Bookmark, 0, len(m.list.Items()))
+	for _, item := range m.list.Items() {
+		entries = append(entries, item.(bookmarkItem).b)
+	}
+	return entries
+}
+
+func (m findModel) Init() tea.Cmd { return m.loadPreview() }
+
+// mainWidth is the width left for the list and preview beside the sidebar.
+func (m findModel) mainWidth() int {
+	if m.sidebar.open {
+		return max(0, m.width-sidebarWidth-1)
+	}
+	return m.width
+}
+
+// showPreview reports whether the preview pane is visible.
+func (m findModel) showPreview() bool {
+	return m.preview && m.mainWidth() >= minPreviewWidth
+}
+
+// loadPreview starts loading the highlighted entry's preview unless it is
+// hidden, cached or already loading.
+func (m findModel) loadPreview() tea.Cmd {
+	if !m.showPreview() {
+		return nil
+	}
+	it, ok := m.list.SelectedItem().(bookmarkItem)
+	if !ok {
+		return nil
+	}
+	if _, seen := m.previews[it.b.Path]; seen {
+		return nil
+	}
+	m.previews[it.b.Path] = nil
+	return loadPreview(it.b.Path)
+}
+
+// resize splits the window between the list and the preview pane.
+func (m *findModel) resize() {
+	height := m.height
+	if !m.embedded {
+		height--
+	}
+	if !m.sidebar.open && m.sidebar.banner() != "" {
+		height--
+	}
+	width := m.mainWidth()
+	if m.showPreview() {
+		width = width * 2 / 5
+	}
+	setListSize(&m.list, width, max(1, height))
+}
 
 func (m findModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case previewMsg:
+		data := msg.data
+		m.previews[msg.path] = &data
+		return m, nil
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
+		if m.prompt.active() {
+			return m.updatePrompt(msg)
+		}
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		if m.showHelp {
+			// Any key closes the help overlay.
+			m.showHelp = false
+			return m, nil
+		}
+		action := m.keys.action(msg)
+		if m.sidebar.focused && msg.String() != "ctrl+c" {
+			if action == "sidebar" {
+				m.sidebar.focused = false
+				return m, nil
+			}
+			highlight := m.highlighted()
+			sidebar, changed := m.sidebar.update(msg)
+			m.sidebar = sidebar
+			if !changed {
+				return m, nil
+			}
+			return m, tea.Batch(m.applyTagFilter(highlight), m.loadPreview())
+		}
+		switch action {
+		case "quit":
+			return m, tea.Quit
+		case "help":
+			m.showHelp = true
+			return m, nil
+		case "sidebar":
+			m.sidebar.open, m.sidebar.focused = true, true
+			m.resize()
+			return m, m.loadPreview()
+		case "toggle-sidebar":
+			m.sidebar.open = !m.sidebar.open
+			m.sidebar.focused = false
+			m.resize()
+			return m, m.loadPreview()
+		case "preview":
+			m.preview = !m.preview
+			// Reopening the pane shows the directory as it is now.
+			if it, ok := m.list.SelectedItem().(bookmarkItem); ok {
+				delete(m.previews, it.b.Path)
+			}
+			m.resize()
+			return m, m.loadPreview()
+		case "mark":
+			if it, ok := m.list.SelectedItem().(bookmarkItem); ok {
+				if m.marked[it.b.Name] {
+					delete(m.marked, it.b.Name)
+				} else {
+					m.marked[it.b.Name] = true
+				}
+			}
+			return m, nil
+		case "mark-all":
+			var names []string
+			for _, item := range m.list.VisibleItems() {
+				names = append(names, item.(bookmarkItem).b.Name)
+			}
+			toggleAllVisible(m.marked, names)
+			return m, nil
+		case "rename":
+			if it, ok := m.list.SelectedItem().(bookmarkItem); ok {
+				m.prompt = newActionPrompt(actionRename, []string{it.b.Name}, it.b.Name)
+			}
+			return m, nil
+		case "tags":
 			if it, ok := m.list.SelectedItem().(bookmarkItem); ok {
-				m.selected = it.b.Name
+				m.prompt = newTagsPrompt(m.targets(), it.b.Tags)
+			}
+			return m, nil
+		case "delete":
+			if targets := m.targets(); len(targets) > 0 {
+				m.prompt = newActionPrompt(actionDelete, targets, "")
+			}
+			return m, nil
+		case "add":
+			prompt, err := addPrompt()
+			if err != nil {
+				return m, m.list.NewStatusMessage(ui.error.Render(err.Error()))
+			}
+			m.prompt = prompt
+			return m, nil
+		case "jump":
+			if m, ok := m.jump(); ok {
 				return m, tea.Quit
 			}
-		case "c":
+		case "copy":
 			if it, ok := m.list.SelectedItem().(bookmarkItem); ok {
-				if err := clipboard.WriteAll(it.b.Path); err != nil {
-					m.list.NewStatusMessage(statusStyle.Render("copy failed: " + err.Error()))
-					return m, nil
+				method, err := m.clipboard.copy(it.b.Path)
+				if err != nil {
+					return m, m.list.NewStatusMessage(ui.error.Render("copy failed: " + err.Error()))
 				}
-				m.list.NewStatusMessage(statusStyle.Render("copied: " + it.b.Path))
-				return m, nil
+				return m, m.list.NewStatusMessage(ui.status.Render(copyStatus(it.b.Path, method)))
 			}
 		}
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
 	case tea.WindowSizeMsg:
-		m.list.SetSize(msg.Width, msg.Height)
+		m.width, m.height = msg.Width, msg.Height
+		m.resize()
 	}
 
 	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msg)
-	return m, cmd
+	return m, tea.Batch(cmd, m.loadPreview())
 }
 
-var tagBannerStyle = lipgloss.NewStyle().
-	Foreground(lipgloss.Color("212")).
-	Bold(true)
+// jump records the bookmarks to return: every marked one in multi mode,
+// otherwise the highlighted one.
+func (m findModel) jump() (findModel, bool) {
+	targets := m.targets()
+	if !m.multi {
+		targets = nil
+		if name := m.highlighted(); name != "" {
+			targets = []string{name}
+		}
+	}
+	if len(targets) == 0 {
+		return m, false
+	}
+	m.selected = targets
+	return m, true
+}
+
+const (
+	// listHeaderLines is how many lines the list draws above its first
+	// item: the title and the status bar, each followed by a blank line.
+	listHeaderLines = 4
+	// doubleClick is the longest gap between two clicks of a double-click.
+	doubleClick = 400 * time.Millisecond
+)
+
+// updateMouse scrolls the list with the wheel, highlights the clicked
+// bookmark or toggles the clicked sidebar tag, and jumps on a double-click.
+func (m findModel) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.prompt.active() || m.showHelp || m.list.FilterState() == list.Filtering {
+		return m, nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.list.CursorUp()
+		return m, m.loadPreview()
+	case tea.MouseButtonWheelDown:
+		m.list.CursorDown()
+		return m, m.loadPreview()
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+	default:
+		return m, nil
+	}
+
+	x, y := msg.X, msg.Y
+	if !m.sidebar.open && m.sidebar.banner() != "" {
+		y--
+	}
+	if m.sidebar.open {
+		if x < sidebarWidth {
+			i, ok := m.sidebar.tagAt(y, m.list.Height())
+			if !ok {
+				return m, nil
+			}
+			highlight := m.highlighted()
+			m.sidebar.cursor = i
+			m.sidebar, _ = m.sidebar.update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+			return m, tea.Batch(m.applyTagFilter(highlight), m.loadPreview())
+		}
+		x -= sidebarWidth + 1
+	}
+	if x < 0 || x >= m.list.Width() {
+		return m, nil
+	}
+
+	const itemLines = 3 // the default delegate's two lines and one of spacing
+	row := y - listHeaderLines
+	if row < 0 || row/itemLines >= m.list.Paginator.PerPage {
+		return m, nil
+	}
+	i := m.list.Paginator.Page*m.list.Paginator.PerPage + row/itemLines
+	if i >= len(m.list.VisibleItems()) {
+		return m, nil
+	}
+	m.list.Select(i)
+	now := time.Now()
+	if i == m.lastClick && now.Sub(m.lastClickAt) < doubleClick {
+		if m, ok := m.jump(); ok {
+			return m, tea.Quit
+		}
+	}
+	m.lastClick, m.lastClickAt = i, now
+	return m, m.loadPreview()
+}
+
+// updatePrompt feeds a key to the pending action and, once submitted, saves
+// it and reloads the list.
+func (m findModel) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	prompt, submitted, cmd := m.prompt.update(msg)
+	if !submitted {
+		m.prompt = prompt
+		return m, cmd
+	}
+	m.prompt = actionPrompt{}
+	saved, selected, status, err := applyAction(m.storePath, prompt)
+	if err != nil {
+		return m, m.list.NewStatusMessage(ui.error.Render(err.Error()))
+	}
+	clear(m.marked)
+	// An edit can change what a path's preview shows, or which path a
+	// bookmark points to.
+	clear(m.previews)
+	m.pool = refreshEntries(saved, m.pool, selected)
+	m.sidebar.setEntries(m.pool)
+	cmds := []tea.Cmd{m.applyTagFilter(selected)}
+	if status != "" {
+		cmds = append(cmds, m.list.NewStatusMessage(ui.status.Render(status)))
+	}
+	cmds = append(cmds, m.loadPreview())
+	return m, tea.Batch(cmds...)
+}
+
+// fitWidth pads or cuts every line of s to width cells, so a pane placed
+// beside it lines up even when a line (like the list's help) is too long.
+func fitWidth(s string, width int) string {
+	return lipgloss.NewStyle().Width(width).Render(lipgloss.NewStyle().MaxWidth(width).Render(s))
+}
+
+// setListSize sizes l and narrows its help line by the help style's padding,
+// which the list leaves out, so the help is shortened rather than cut.
+func setListSize(l *list.Model, width, height int) {
+	l.SetSize(width, height)
+	l.Help.Width = max(1, width-l.Styles.HelpStyle.GetHorizontalFrameSize())
+}
 
 func (m findModel) View() string {
 	var b strings.Builder
-	if len(m.tags) > 0 {
-		b.WriteString(tagBannerStyle.Render("filters: "+strings.Join(m.tags, ", ")) + "\n")
+	if banner := m.sidebar.banner(); banner != "" && !m.sidebar.open {
+		b.WriteString(ui.banner.Render(banner) + "\n")
+	}
+	body := m.list.View()
+	if m.showPreview() {
+		body = fitWidth(body, m.list.Width())
+		if it, ok := m.list.SelectedItem().(bookmarkItem); ok {
+			pane := renderPreview(it.b, m.previews[it.b.Path], m.mainWidth()-m.list.Width()-1, m.list.Height())
+			body = lipgloss.JoinHorizontal(lipgloss.Top, body, " ", pane)
+		}
+	}
+	if m.sidebar.open {
+		body = lipgloss.JoinHorizontal(lipgloss.Top, m.sidebar.View(m.list.Height()), " ", body)
+	}
+	if m.showHelp {
+		body = helpOverlay(m.keys.fullHelp(findActions, [][2]string{
+			{"↑/k ↓/j", "move"},
+			{"/", "filter the list"},
+			{"space", "toggle a tag (tag sidebar)"},
+			{"m", "match any or all tags (tag sidebar)"},
+			{"x", "clear the active tags (tag sidebar)"},
+			{"mouse", "click to highlight, double-click to jump, wheel to scroll"},
+		}), m.width, m.list.Height())
+	}
+	b.WriteString(body)
+	if m.embedded {
+		return b.String()
+	}
+	if m.prompt.active() {
+		b.WriteString("\n" + m.prompt.View())
+		return b.String()
+	}
+	help := m.footer(m.keys.shortHelp("jump", "mark", "copy", "preview", "sidebar", "help", "quit") + "  •  /: filter")
+	if m.width > 0 {
+		help = ansi.Truncate(help, m.width, "…")
 	}
-	b.WriteString(m.list.View())
-	help := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("enter: jump  •  c: copy path  •  /: filter  •  q: quit")
 	b.WriteString("\n" + help)
 	return b.String()
 }
 
+// footer returns the key help line, showing help unless the sidebar is
+// focused, with the number of marked bookmarks in front.
+func (m findModel) footer(help string) string {
+	help = ui.footer.Render(help)
+	if m.sidebar.focused {
+		help = ui.footer.Render("space: toggle tag  •  m: any/all  •  x: clear  •  " + m.keys.label("sidebar") + ": back to list")
+	}
+	if n := len(m.marked); n > 0 {
+		help = ui.status.Render(fmt.Sprintf("%d marked", n)) + "  " + help
+	}
+	return help
+}
+
 // ----------------
 // TABLE
 // ----------------
 
 type tableModel struct {
-	table    table.Model
-	selected string
+	table     table.Model
+	selected  []string
+	storePath string
+	prompt    actionPrompt
+	status    string
+	multi     bool
+	marked    map[string]bool
+	// all holds every bookmark the table was opened with; entries are the
+	// rows shown, filtered and sorted.
+	all     []bookmarks.Bookmark
+	entries []bookmarks.Bookmark
+	columns []tableColumn
+	// sortCol indexes columns, or is -1 for store order.
+	sortCol   int
+	sortDesc  bool
+	filter    textinput.Model
+	filtering bool
+	width     int
+	// visits and gitCells feed the visit and git columns; git cells are
+	// keyed by path.
+	visits    bookmarks.Visits
+	gitCells  map[string]string
+	styles    table.Styles
+	keys      keyMap
+	clipboard clipboardWriter
+	showHelp  bool
+	// lastClick is the row last clicked and when, to detect double-clicks.
+	lastClick   int
+	lastClickAt time.Time
 }
 
-var statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
-
-func newTableModel(rows []table.Row, title string) tableModel {
-	columns := []table.Column{
-		{Title: "Name", Width: 18},
-		{Title: "Path", Width: 48},
-		{Title: "Tags", Width: 20},
-		{Title: "Created", Width: 10},
-	}
+// gitStatusMsg delivers git results for the table rows.
+type gitStatusMsg []gitinfo.Result
 
+func newTableModel(storePath string, entries []bookmarks.Bookmark, title string, columns []tableColumn) tableModel {
 	t := table.New(
-		table.WithColumns(columns),
-		table.WithRows(rows),
+		table.WithColumns(tableHeader(columns, layoutColumns(columns, 0), -1, false)),
 		table.WithFocused(true),
 	)
-	styles := table.DefaultStyles()
-	styles.Header = styles.Header.Bold(true)
-	styles.Selected = styles.Selected.Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57")).Bold(false)
+	styles := ui.table
 	t.SetStyles(styles)
 
+	filter := textinput.New()
+	filter.Prompt = "/"
+
 	_ = title // shown in View
-	return tableModel{table: t}
+	m := tableModel{
+		table:     t,
+		storePath: storePath,
+		marked:    map[string]bool{},
+		columns:   columns,
+		sortCol:   -1,
+		filter:    filter,
+		gitCells:  map[string]string{},
+		styles:    styles,
+		clipboard: newClipboardWriter("auto"),
+		lastClick: -1,
+	}
+	if m.hasColumn("visits") || m.hasColumn("last") {
+		path, err := bookmarks.DefaultVisitsPath()
+		if err == nil {
+			m.visits, err = bookmarks.LoadVisits(path)
+		}
+		if err != nil {
+			m.status = ui.error.Render(err.Error())
+		}
+	}
+	m.setKeyMap(defaultKeyMap())
+	m.setEntries(entries)
+	return m
+}
+
+// setKeyMap installs keys, freeing the table's paging keys they take.
+func (m *tableModel) setKeyMap(keys keyMap) {
+	m.keys = keys
+	tk := table.DefaultKeyMap()
+	m.table.KeyMap.PageUp, m.table.KeyMap.PageDown = tk.PageUp, tk.PageDown
+	m.table.KeyMap.HalfPageUp, m.table.KeyMap.HalfPageDown = tk.HalfPageUp, tk.HalfPageDown
+	for _, b := range []*key.Binding{&m.table.KeyMap.PageUp, &m.table.KeyMap.PageDown, &m.table.KeyMap.HalfPageUp, &m.table.KeyMap.HalfPageDown} {
+		keys.unbind(b)
+	}
 }
 
-func (m tableModel) Init() tea.Cmd { return nil }
+// tableActions are the key actions the table view offers.
+var tableActions = []string{"jump", "mark", "copy", "rename", "tags", "delete", "add", "mark-all", "help", "quit"}
+
+func (m tableModel) hasColumn(key string) bool {
+	for _, c := range m.columns {
+		if c.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// setEntries replaces the bookmarks the table shows.
+func (m *tableModel) setEntries(entries []bookmarks.Bookmark) {
+	m.all = entries
+	m.refreshRows()
+}
+
+func (m tableModel) cellContext() cellContext {
+	return cellContext{visits: m.visits, git: m.gitCells, now: time.Now()}
+}
+
+// refreshRows filters and sorts the bookmarks and rebuilds the rows, keeping
+// the cursor on the highlighted bookmark when it is still shown.
+func (m *tableModel) refreshRows() {
+	current, hadCurrent := m.selectedEntry()
+	cursor := m.table.Cursor()
+
+	ctx := m.cellContext()
+	m.entries = filterBySubstring(m.all, strings.TrimSpace(m.filter.Value()))
+	if m.sortCol >= 0 {
+		m.entries = slices.Clone(m.entries)
+		col := m.columns[m.sortCol]
+		slices.SortStableFunc(m.entries, func(a, b bookmarks.Bookmark) int {
+			var n int
+			if col.compare != nil {
+				n = col.compare(a, b, ctx)
+			} else {
+				n = strings.Compare(strings.ToLower(col.cell(a, ctx)), strings.ToLower(col.cell(b, ctx)))
+			}
+			if m.sortDesc {
+				return -n
+			}
+			return n
+		})
+	}
+
+	widths := layoutColumns(m.columns, m.width)
+	rows := make([]table.Row, 0, len(m.entries))
+	for _, e := range m.entries {
+		row := make(table.Row, len(m.columns))
+		for i, c := range m.columns {
+			cell := c.cell(e, ctx)
+			switch c.key {
+			case "name":
+				if m.marked[e.Name] {
+					cell = "✓ " + cell
+				}
+			case "path":
+				cell = middleEllipsis(cell, widths[i])
+			}
+			row[i] = cell
+		}
+		rows = append(rows, row)
+	}
+	// Shrink the rows before the columns change so the table never renders
+	// a row with more cells than columns.
+	m.table.SetRows(nil)
+	m.table.SetColumns(tableHeader(m.columns, widths, m.sortCol, m.sortDesc))
+	m.table.SetRows(rows)
+
+	cursor = min(cursor, max(0, len(m.entries)-1))
+	if hadCurrent {
+		if i := findEntry(m.entries, current.Name); i >= 0 {
+			cursor = i
+		}
+	}
+	m.table.SetCursor(cursor)
+}
+
+// sortBy sorts by the column at index i, reversing the order when it is
+// already sorted by it; i < 0 restores store order.
+func (m *tableModel) sortBy(i int) {
+	switch {
+	case i < 0:
+		m.sortCol, m.sortDesc = -1, false
+	case i == m.sortCol:
+		m.sortDesc = !m.sortDesc
+	default:
+		m.sortCol, m.sortDesc = i, false
+	}
+	m.refreshRows()
+}
+
+// targets returns the marked bookmark names in row order, or the highlighted
+// one when none is marked.
+func (m tableModel) targets() []string {
+	var names []string
+	for _, e := range m.entries {
+		if m.marked[e.Name] {
+			names = append(names, e.Name)
+		}
+	}
+	if len(names) == 0 {
+		if e, ok := m.selectedEntry(); ok {
+			names = []string{e.Name}
+		}
+	}
+	return names
+}
+
+// selectedEntry returns the bookmark in the highlighted row.
+func (m tableModel) selectedEntry() (bookmarks.Bookmark, bool) {
+	i := m.table.Cursor()
+	if i < 0 || i >= len(m.entries) {
+		return bookmarks.Bookmark{}, false
+	}
+	return m.entries[i], true
+}
+
+func (m tableModel) Init() tea.Cmd {
+	if !m.hasColumn("git") {
+		return nil
+	}
+	return loadGitStatus(m.all)
+}
+
+// loadGitStatus inspects the bookmarks' repositories off the UI goroutine.
+func loadGitStatus(entries []bookmarks.Bookmark) tea.Cmd {
+	dirs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		dirs = append(dirs, e.Path)
+	}
+	return func() tea.Msg {
+		return gitStatusMsg(gitCache.InspectAll(context.Background(), dirs, gitTimeout, gitWorkers))
+	}
+}
 
 func (m tableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		if m.prompt.active() {
+			return m.updatePrompt(msg)
+		}
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+		if m.showHelp {
+			m.showHelp = false
+			return m, nil
+		}
+		m.status = ""
+		switch key := msg.String(); key {
+		case "ctrl+c":
 			return m, tea.Quit
-		case "enter":
-			row := m.table.SelectedRow()
-			if len(row) >= 1 {
-				m.selected = row[0]
+		case "/":
+			m.filtering = true
+			return m, m.filter.Focus()
+		case "esc":
+			if m.filter.Value() != "" {
+				m.filter.SetValue("")
+				m.refreshRows()
+			}
+			return m, nil
+		case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			if i := int(key[0]-'0') - 1; i < len(m.columns) {
+				m.sortBy(i)
 			}
+			return m, nil
+		}
+		switch action := m.keys.action(msg); action {
+		case "quit":
 			return m, tea.Quit
-		case "c":
-			row := m.table.SelectedRow()
-			if len(row) >= 2 {
-				_ = clipboard.WriteAll(row[1])
+		case "help":
+			m.showHelp = true
+			return m, nil
+		case "mark":
+			if e, ok := m.selectedEntry(); ok {
+				if m.marked[e.Name] {
+					delete(m.marked, e.Name)
+				} else {
+					m.marked[e.Name] = true
+				}
+				m.refreshRows()
+			}
+			return m, nil
+		case "mark-all":
+			names := make([]string, 0, len(m.entries))
+			for _, e := range m.entries {
+				names = append(names, e.Name)
+			}
+			toggleAllVisible(m.marked, names)
+			m.refreshRows()
+			return m, nil
+		case "rename", "tags", "delete":
+			e, ok := m.selectedEntry()
+			if !ok {
+				return m, nil
+			}
+			switch action {
+			case "rename":
+				m.prompt = newActionPrompt(actionRename, []string{e.Name}, e.Name)
+			case "tags":
+				m.prompt = newTagsPrompt(m.targets(), e.Tags)
+			case "delete":
+				m.prompt = newActionPrompt(actionDelete, m.targets(), "")
+			}
+			return m, nil
+		case "add":
+			prompt, err := addPrompt()
+			if err != nil {
+				m.status = ui.error.Render(err.Error())
+				return m, nil
+			}
+			m.prompt = prompt
+			return m, nil
+		case "jump":
+			return m.jump(), tea.Quit
+		case "copy":
+			if e, ok := m.selectedEntry(); ok {
+				method, err := m.clipboard.copy(e.Path)
+				if err != nil {
+					m.status = ui.error.Render("copy failed: " + err.Error())
+				} else {
+					m.status = ui.status.Render(copyStatus(e.Path, method))
+				}
 			}
 			return m, nil
 		}
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
 	case tea.WindowSizeMsg:
-		// keep the table responsive to terminal size
+		m.width = msg.Width
+		m.table.SetWidth(msg.Width)
 		m.table.SetHeight(max(5, msg.Height-3))
+		m.refreshRows()
+		return m, nil
+	case gitStatusMsg:
+		// Keyed by directory: rows may have changed since the load started.
+		for _, r := range msg {
+			m.gitCells[r.Dir] = formatGitCell(r)
+		}
+		m.refreshRows()
+		return m, nil
 	}
 
 	var cmd tea.Cmd
@@ -158,64 +890,342 @@ func (m tableModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// jump records the bookmarks to return: every marked one in multi mode,
+// otherwise the highlighted one.
+func (m tableModel) jump() tableModel {
+	if m.multi {
+		m.selected = m.targets()
+	} else if e, ok := m.selectedEntry(); ok {
+		m.selected = []string{e.Name}
+	}
+	return m
+}
+
+// tableHeaderLines is how many lines the view draws above the table: the
+// title line.
+const tableHeaderLines = 1
+
+// updateMouse scrolls with the wheel, highlights the clicked row and jumps
+// on a double-click.
+func (m tableModel) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.prompt.active() || m.showHelp {
+		return m, nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.table.MoveUp(1)
+		return m, nil
+	case tea.MouseButtonWheelDown:
+		m.table.MoveDown(1)
+		return m, nil
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+	default:
+		return m, nil
+	}
+	i, ok := m.rowAt(msg.Y - tableHeaderLines)
+	if !ok {
+		return m, nil
+	}
+	// Step like the arrow keys: SetCursor would re-scroll the table and move
+	// the rows out from under the pointer.
+	if d := i - m.table.Cursor(); d < 0 {
+		m.table.MoveUp(-d)
+	} else if d > 0 {
+		m.table.MoveDown(d)
+	}
+	now := time.Now()
+	if i == m.lastClick && now.Sub(m.lastClickAt) < doubleClick {
+		return m.jump(), tea.Quit
+	}
+	m.lastClick, m.lastClickAt = i, now
+	return m, nil
+}
+
+// rowMarker replaces the cursor row when locating rows on screen.
+const rowMarker = "\x00cursor"
+
+// rowAt returns the row drawn on line y of the table's view. The table keeps
+// its scroll offset private, so the cursor row is found by rendering it as a
+// marker and the clicked row counted from there.
+func (m tableModel) rowAt(y int) (int, bool) {
+	t := m.table
+	styles := m.styles
+	styles.Selected = lipgloss.NewStyle().Transform(func(string) string { return rowMarker })
+	t.SetStyles(styles)
+	lines := strings.Split(t.View(), "\n")
+	if y < 0 || y >= len(lines) {
+		return 0, false
+	}
+	// The column headers come first.
+	if y < m.styles.Header.GetVerticalFrameSize()+1 {
+		return 0, false
+	}
+	for cursorLine, line := range lines {
+		if strings.Contains(line, rowMarker) {
+			i := t.Cursor() + y - cursorLine
+			return i, i >= 0 && i < len(m.entries)
+		}
+	}
+	return 0, false
+}
+
+// updateFilter edits the filter, narrowing the rows as it changes. enter
+// keeps the filter and returns to the table; esc clears it.
+func (m tableModel) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filtering = false
+		m.filter.Blur()
+		return m, nil
+	case tea.KeyEsc:
+		m.filtering = false
+		m.filter.Blur()
+		m.filter.SetValue("")
+		m.refreshRows()
+		return m, nil
+	case tea.KeyUp, tea.KeyDown:
+		var cmd tea.Cmd
+		m.table, cmd = m.table.Update(msg)
+		return m, cmd
+	}
+	before := m.filter.Value()
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	if m.filter.Value() != before {
+		m.refreshRows()
+	}
+	return m, cmd
+}
+
+// updatePrompt feeds a key to the pending action and, once submitted, saves
+// it and reloads the rows.
+func (m tableModel) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	prompt, submitted, cmd := m.prompt.update(msg)
+	if !submitted {
+		m.prompt = prompt
+		return m, cmd
+	}
+	m.prompt = actionPrompt{}
+	saved, selected, status, err := applyAction(m.storePath, prompt)
+	if err != nil {
+		m.status = ui.error.Render(err.Error())
+		return m, nil
+	}
+	clear(m.marked)
+	m.setEntries(refreshEntries(saved, m.all, selected))
+	if i := findEntry(m.entries, selected); i >= 0 {
+		m.table.SetCursor(i)
+	}
+	m.status = ui.status.Render(status)
+	return m, m.Init()
+}
+
 func (m tableModel) View() string {
 	header := lipgloss.NewStyle().Bold(true).Render("bm table")
-	help := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("enter: jump  •  c: copy path  •  q: quit")
-	return header + "\n" + m.table.View() + "\n" + help
+	if m.filtering || m.filter.Value() != "" {
+		header += "  " + m.filter.View()
+	}
+	footer := ui.footer.Render(m.keys.shortHelp("jump", "mark", "copy", "help", "quit") + "  •  /: filter  •  1-9: sort")
+	if n := len(m.marked); n > 0 {
+		footer = ui.status.Render(fmt.Sprintf("%d marked", n)) + "  " + footer
+	}
+	body := m.table.View()
+	if m.showHelp {
+		body = helpOverlay(m.keys.fullHelp(tableActions, [][2]string{
+			{"↑/k ↓/j", "move"},
+			{"/", "filter the rows"},
+			{"esc", "clear the filter"},
+			{"1-9", "sort by that column, again to reverse"},
+			{"0", "store order"},
+			{"mouse", "click to highlight, double-click to jump, wheel to scroll"},
+		}), m.width, m.table.Height()+2)
+	}
+	switch {
+	case m.prompt.active():
+		footer = m.prompt.View()
+	case m.status != "":
+		footer = m.status
+	}
+	if m.width > 0 {
+		footer = ansi.Truncate(footer, m.width, "…")
+	}
+	return header + "\n" + body + "\n" + footer
 }
 
-// Helpers
+// ----------------
+// PICK (multi-select)
+// ----------------
 
-func buildTableRows(entries []bookmarks.Bookmark) []table.Row {
-	rows := make([]table.Row, 0, len(entries))
-	for _, e := range entries {
-		created := ""
-		if !e.CreatedAt.IsZero() {
-			created = e.CreatedAt.In(time.Local).Format("2006-01-02")
-		}
-		rows = append(rows, table.Row{
-			e.Name,
-			e.Path,
-			strings.Join(e.Tags, ","),
-			created,
-		})
+// pickItem is a proposal the user can accept or reject; key identifies it in
+// the result.
+type pickItem struct {
+	key   string
+	title string
+	desc  string
+}
+
+func (i pickItem) Title() string       { return i.title }
+func (i pickItem) Description() string { return i.desc }
+func (i pickItem) FilterValue() string { return i.title + " " + i.desc }
+
+// pickDelegate renders items with a checkbox reflecting the shared selection.
+type pickDelegate struct {
+	list.DefaultDelegate
+	checked map[string]bool
+}
+
+func (d pickDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	it, ok := item.(pickItem)
+	if !ok {
+		return
+	}
+	box := "[ ] "
+	if d.checked[it.key] {
+		box = "[x] "
 	}
-	return rows
+	it.title = box + it.title
+	d.DefaultDelegate.Render(w, m, index, it)
 }
 
-func runFindTUI(entries []bookmarks.Bookmark, title string, tags []string) (string, error) {
-	items := make([]list.Item, 0, len(entries))
-	for _, e := range entries {
-		items = append(items, bookmarkItem{b: e})
+type pickModel struct {
+	list      list.Model
+	checked   map[string]bool
+	confirmed bool
+}
+
+func newPickModel(items []pickItem, title string, preselect bool) pickModel {
+	checked := map[string]bool{}
+	listItems := make([]list.Item, 0, len(items))
+	for _, it := range items {
+		checked[it.key] = preselect
+		listItems = append(listItems, it)
 	}
-	m := newFindModel(items, title, tags)
-	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(os.Stderr))
+	delegate := pickDelegate{DefaultDelegate: list.NewDefaultDelegate(), checked: checked}
+	delegate.Styles = ui.item
+
+	lm := list.New(listItems, delegate, 0, 0)
+	lm.Title = title
+	lm.Styles = ui.list
+	lm.Help.Styles = ui.help
+	lm.SetShowStatusBar(true)
+	lm.SetFilteringEnabled(true)
+	return pickModel{list: lm, checked: checked}
+}
+
+func (m pickModel) Init() tea.Cmd { return nil }
+
+func (m pickModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		switch msg.String() {
+		case "enter":
+			m.confirmed = true
+			return m, tea.Quit
+		case " ":
+			if it, ok := m.list.SelectedItem().(pickItem); ok {
+				m.checked[it.key] = !m.checked[it.key]
+			}
+			return m, nil
+		case "a":
+			all := true
+			for _, item := range m.list.VisibleItems() {
+				all = all && m.checked[item.(pickItem).key]
+			}
+			for _, item := range m.list.VisibleItems() {
+				m.checked[item.(pickItem).key] = !all
+			}
+			return m, nil
+		}
+	case tea.WindowSizeMsg:
+		setListSize(&m.list, msg.Width, msg.Height-1)
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m pickModel) View() string {
+	help := ui.footer.Render("space: toggle  •  a: toggle all  •  enter: confirm  •  /: filter  •  q: cancel")
+	return m.list.View() + "\n" + help
+}
+
+// selectedKeys returns the checked keys in list order.
+func (m pickModel) selectedKeys() []string {
+	var keys []string
+	for _, item := range m.list.Items() {
+		if it := item.(pickItem); m.checked[it.key] {
+			keys = append(keys, it.key)
+		}
+	}
+	return keys
+}
+
+// Helpers
+
+// runFindTUI returns the chosen bookmark names: at most one unless multi is
+// set.
+func runFindTUI(storePath string, entries []bookmarks.Bookmark, title string, tags []string, keys keyMap, clip clipboardWriter, multi bool) ([]string, error) {
+	m := newFindModel(storePath, entries, title, tags)
+	m.setKeyMap(keys)
+	m.clipboard = clip
+	m.multi = multi
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithOutput(os.Stderr))
 	final, err := p.Run()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	fm, ok := final.(findModel)
 	if !ok {
-		return "", fmt.Errorf("unexpected model")
+		return nil, fmt.Errorf("unexpected model")
 	}
 	return fm.selected, nil
 }
 
-func runTableTUI(entries []bookmarks.Bookmark, title string) (string, error) {
-	rows := buildTableRows(entries)
-	m := newTableModel(rows, title)
-	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(os.Stderr))
+// runTableTUI returns the chosen bookmark names: at most one unless multi is
+// set.
+func runTableTUI(storePath string, entries []bookmarks.Bookmark, title string, columns []tableColumn, keys keyMap, clip clipboardWriter, multi bool) ([]string, error) {
+	m := newTableModel(storePath, entries, title, columns)
+	m.setKeyMap(keys)
+	m.clipboard = clip
+	m.multi = multi
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithOutput(os.Stderr))
 	final, err := p.Run()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	tm, ok := final.(tableModel)
 	if !ok {
-		return "", fmt.Errorf("unexpected model")
+		return nil, fmt.Errorf("unexpected model")
 	}
 	return tm.selected, nil
 }
 
+// runPickTUI lets the user choose among items and returns the chosen keys,
+// or nil if the picker was cancelled.
+func runPickTUI(items []pickItem, title string, preselect bool) ([]string, error) {
+	m := newPickModel(items, title, preselect)
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(os.Stderr))
+	final, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+	pm, ok := final.(pickModel)
+	if !ok {
+		return nil, fmt.Errorf("unexpected model")
+	}
+	if !pm.confirmed {
+		return nil, nil
+	}
+	return pm.selectedKeys(), nil
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/navio/bookmarks/internal/bookmarks"
+)
+
+// drain runs cmd and feeds the messages it produces back into m, following
+// batches, until no commands are left.
+func drain(t *testing.T, m tea.Model, cmd tea.Cmd) tea.Model {
+	t.Helper()
+	queue := []tea.Cmd{cmd}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		if c == nil {
+			continue
+		}
+		switch msg := c().(type) {
+		case tea.BatchMsg:
+			queue = append(queue, msg...)
+		case previewMsg:
+			var next tea.Cmd
+			m, next = m.Update(msg)
+			queue = append(queue, next)
+		}
+	}
+	return m
+}
+
+func TestFindModel_PreviewPane(t *testing.T) {
+	dir := t.TempDir()
+	api := filepath.Join(dir, "api")
+	if err := os.MkdirAll(filepath.Join(api, "internal"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(api, "README.md"), []byte("# API service\nhandles requests\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	entries := []bookmarks.Bookmark{
+		{Name: "api", Path: api, Tags: []string{"work"}, Description: "public API"},
+		{Name: "gone", Path: filepath.Join(dir, "gone")},
+	}
+
+	var m tea.Model = newFindModel("", entries, "bm find", nil)
+	m, cmd := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m = drain(t, m, cmd)
+
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"# API service", "internal/", "tags: work", "p: preview"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if fm := m.(findModel); fm.previews[filepath.Join(dir, "gone")] != nil {
+		t.Fatalf("preview loaded synchronously")
+	}
+	if !strings.Contains(ansi.Strip(m.View()), "loading…") {
+		t.Fatalf("expected loading placeholder:\n%s", ansi.Strip(m.View()))
+	}
+	m = drain(t, m, cmd)
+	if !strings.Contains(ansi.Strip(m.View()), "no such file or directory") {
+		t.Fatalf("expected error for missing directory:\n%s", ansi.Strip(m.View()))
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if strings.Contains(ansi.Strip(m.View()), "loading…") || strings.Contains(ansi.Strip(m.View()), "no such file") {
+		t.Fatalf("preview still shown after toggling off")
+	}
+}
+
+func TestFindModel_PreviewReloadsAndStripsEscapes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("\x1b[2J\x1b[31mred\x1b[0m\a text\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	entries := []bookmarks.Bookmark{{Name: "api", Path: dir}}
+	var m tea.Model = newFindModel("", entries, "bm find", nil)
+	m, cmd := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m = drain(t, m, cmd)
+	if view := m.View(); strings.Contains(view, "\x1b[2J") || strings.Contains(view, "\a") || !strings.Contains(ansi.Strip(view), "red text") {
+		t.Fatalf("README not sanitized:\n%q", view)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "added.txt"), nil, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = drain(t, m, cmd)
+	if !strings.Contains(ansi.Strip(m.View()), "added.txt") {
+		t.Fatalf("preview not reloaded after toggling:\n%s", ansi.Strip(m.View()))
+	}
+}
+
+func TestFindModel_PreviewHiddenWhenNarrow(t *testing.T) {
+	entries := []bookmarks.Bookmark{{Name: "api", Path: t.TempDir()}}
+	var m tea.Model = newFindModel("", entries, "bm find", nil)
+	m, cmd := m.Update(tea.WindowSizeMsg{Width: 60, Height: 20})
+	if m.(findModel).showPreview() {
+		t.Fatalf("preview shown at width 60")
+	}
+	if drain(t, m, cmd).(findModel).previews[entries[0].Path] != nil {
+		t.Fatalf("preview loaded while hidden")
+	}
+}
+
+func keyMsg(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "ctrl+u":
+		return tea.KeyMsg{Type: tea.KeyCtrlU}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "shift+tab":
+		return tea.KeyMsg{Type: tea.KeyShiftTab}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func mustColumns(t *testing.T, keys ...string) []tableColumn {
+	t.Helper()
+	columns, err := lookupColumns(keys)
+	if err != nil {
+		t.Fatalf("lookupColumns() error = %v", err)
+	}
+	return columns
+}
+
+// press sends keys to m one at a time, ignoring the commands they return.
+func press(m tea.Model, keys ...string) tea.Model {
+	for _, k := range keys {
+		m, _ = m.Update(keyMsg(k))
+	}
+	return m
+}
+
+func TestFindModel_Actions(t *testing.T) {
+	dir := t.TempDir()
+	storePath := filepath.Join(dir, "bm.tsv")
+	entries := []bookmarks.Bookmark{
+		{Name: "api", Path: "/src/api", Tags: []string{"work"}},
+		{Name: "web", Path: "/src/web"},
+	}
+	if err := bookmarks.Save(storePath, entries); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	var m tea.Model = newFindModel(storePath, entries, "bm find", nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 60, Height: 20})
+
+	m = press(m, "r", "ctrl+u", "a", "p", "i", "2", "enter")
+	m = press(m, "t", "ctrl+u", "h", "o", "m", "e", "enter")
+	saved, _ := bookmarks.Load(storePath)
+	if saved[0].Name != "api2" || !reflect.DeepEqual(saved[0].Tags, []string{"home"}) || saved[0].UpdatedAt.IsZero() {
+		t.Fatalf("saved = %#v", saved[0])
+	}
+	if items := m.(findModel).entries(); len(items) != 2 || items[0].Name != "api2" {
+		t.Fatalf("list = %#v", items)
+	}
+	if !strings.Contains(ansi.Strip(m.View()), "api2") {
+		t.Fatalf("view does not show rename:\n%s", ansi.Strip(m.View()))
+	}
+
+	// Renaming onto an existing name fails without saving.
+	m = press(m, "r", "ctrl+u", "w", "e", "b", "enter")
+	if saved, _ := bookmarks.Load(storePath); saved[0].Name != "api2" {
+		t.Fatalf("rename over existing name saved: %#v", saved)
+	}
+
+	// Anything but "y" cancels a delete.
+	m = press(m, "d", "n")
+	if saved, _ := bookmarks.Load(storePath); len(saved) != 2 {
+		t.Fatalf("cancelled delete removed bookmarks: %#v", saved)
+	}
+	m = press(m, "d", "y")
+	saved, _ = bookmarks.Load(storePath)
+	if len(saved) != 1 || saved[0].Name != "web" {
+		t.Fatalf("after delete saved = %#v", saved)
+	}
+	if items := m.(findModel).entries(); len(items) != 1 || items[0].Name != "web" {
+		t.Fatalf("deleted entry still listed: %#v", items)
+	}
+
+	oldCwd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(oldCwd) })
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	m = press(m, "a", "ctrl+u", "h", "e", "r", "e", "enter")
+	saved, _ = bookmarks.Load(storePath)
+	if len(saved) != 2 || saved[1].Name != "here" || saved[1].Path != dir {
+		t.Fatalf("after add saved = %#v", saved)
+	}
+	if items := m.(findModel).entries(); len(items) != 2 || items[1].Name != "here" {
+		t.Fatalf("added entry not listed: %#v", items)
+	}
+}
+
+func TestTableModel_Actions(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "bm.tsv")
+	entries := []bookmarks.Bookmark{
+		{Name: "api", Path: "/src/api"},
+		{Name: "web", Path: "/src/web"},
+	}
+	if err := bookmarks.Save(storePath, entries); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	var m tea.Model = newTableModel(storePath, entries, "bm table", mustColumns(t, defaultTableColumns...))
+	m = press(m, "d", "y")
+	m = press(m, "t", "o", "p", "s", "enter")
+	saved, _ := bookmarks.Load(storePath)
+	if len(saved) != 1 || saved[0].Name != "web" || !reflect.DeepEqual(saved[0].Tags, []string{"ops"}) {
+		t.Fatalf("saved = %#v", saved)
+	}
+	view := ansi.Strip(m.View())
+	if strings.Contains(view, "/src/api") || !strings.Contains(view, "ops") || !strings.Contains(view, "tagged web") {
+		t.Fatalf("view:\n%s", view)
+	}
+}
+
+func TestFindModel_MultiSelect(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "bm.tsv")
+	entries := []bookmarks.Bookmark{
+		{Name: "api", Path: "/src/api", Tags: []string{"go", "work"}},
+		{Name: "docs", Path: "/src/docs"},
+		{Name: "web", Path: "/src/web", Tags: []string{"js"}},
+	}
+	if err := bookmarks.Save(storePath, entries); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	fm := newFindModel(storePath, entries, "bm find", nil)
+	fm.multi = true
+	var m tea.Model = fm
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 60, Height: 20})
+
+	// Mark web then api; enter returns them in list order.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = press(m, " ")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m = press(m, " ")
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "✓ api") || !strings.Contains(view, "✓ web") || !strings.Contains(view, "2 marked") {
+		t.Fatalf("view:\n%s", view)
+	}
+	if got := m.(findModel).targets(); !reflect.DeepEqual(got, []string{"api", "web"}) {
+		t.Fatalf("targets = %v", got)
+	}
+
+	// Tags and deletes apply to every marked bookmark. A bulk retag starts
+	// empty and adds or removes tags on each, keeping their own.
+	m = press(m, "t")
+	if v := m.(findModel).prompt.input.Value(); v != "" {
+		t.Fatalf("bulk tags prompt starts with %q", v)
+	}
+	m = press(m, "+", "o", "l", "d", ",", "-", "w", "o", "r", "k", "enter")
+	saved, _ := bookmarks.Load(storePath)
+	if !reflect.DeepEqual(saved[0].Tags, []string{"go", "old"}) || len(saved[1].Tags) != 0 || !reflect.DeepEqual(saved[2].Tags, []string{"js", "old"}) {
+		t.Fatalf("retag saved = %#v", saved)
+	}
+	if len(m.(findModel).marked) != 0 {
+		t.Fatalf("marks kept after action")
+	}
+
+	// "*" marks every visible bookmark, and again clears them.
+	m = press(m, "*")
+	if got := m.(findModel).targets(); len(got) != 3 {
+		t.Fatalf("targets after * = %v", got)
+	}
+	m = press(m, "*", "*", "d", "y")
+	if saved, _ := bookmarks.Load(storePath); len(saved) != 0 {
+		t.Fatalf("bulk delete saved = %#v", saved)
+	}
+}
+
+func TestFindModel_EnterReturnsSelection(t *testing.T) {
+	entries := []bookmarks.Bookmark{{Name: "api", Path: "/src/api"}, {Name: "web", Path: "/src/web"}}
+	for _, multi := range []bool{false, true} {
+		fm := newFindModel("", entries, "bm find", nil)
+		fm.multi = multi
+		var m tea.Model = fm
+		m, _ = m.Update(tea.WindowSizeMsg{Width: 60, Height: 20})
+		m = press(m, " ")
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m = press(m, " ", "enter")
+		want := []string{"web"}
+		if multi {
+			want = []string{"api", "web"}
+		}
+		if got := m.(findModel).selected; !reflect.DeepEqual(got, want) {
+			t.Fatalf("multi=%v selected = %v, want %v", multi, got, want)
+		}
+	}
+}
+
+func TestTableModel_MultiSelect(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "bm.tsv")
+	entries := []bookmarks.Bookmark{
+		{Name: "api", Path: "/src/api"},
+		{Name: "docs", Path: "/src/docs"},
+		{Name: "web", Path: "/src/web"},
+	}
+	if err := bookmarks.Save(storePath, entries); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	tm := newTableModel(storePath, entries, "bm table", mustColumns(t, defaultTableColumns...))
+	tm.multi = true
+	var m tea.Model = tm
+	m = press(m, " ")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = press(m, " ")
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "✓ api") || !strings.Contains(view, "✓ web") {
+		t.Fatalf("view:\n%s", view)
+	}
+	m = press(m, "enter")
+	if got := m.(tableModel).selected; !reflect.DeepEqual(got, []string{"api", "web"}) {
+		t.Fatalf("selected = %v", got)
+	}
+
+	m = press(m, "d", "y")
+	saved, _ := bookmarks.Load(storePath)
+	if len(saved) != 1 || saved[0].Name != "docs" {
+		t.Fatalf("bulk delete saved = %#v", saved)
+	}
+}
+
+func tableNames(m tea.Model) []string {
+	var names []string
+	for _, e := range m.(tableModel).entries {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+func TestTableModel_SortAndFilter(t *testing.T) {
+	now := time.Now()
+	entries := []bookmarks.Bookmark{
+		{Name: "web", Path: "/src/web", Tags: []string{"work"}, CreatedAt: now.Add(-time.Hour)},
+		{Name: "api", Path: "/src/api", Tags: []string{"work"}, CreatedAt: now},
+		{Name: "Docs", Path: "/src/docs", CreatedAt: now.Add(-2 * time.Hour)},
+	}
+	var m tea.Model = newTableModel("", entries, "bm table", mustColumns(t, "name", "path", "created"))
+
+	m = press(m, "1")
+	if got := tableNames(m); !reflect.DeepEqual(got, []string{"api", "Docs", "web"}) {
+		t.Fatalf("sorted by name = %v", got)
+	}
+	if !strings.Contains(ansi.Strip(m.View()), "Name ▲") {
+		t.Fatalf("no sort indicator:\n%s", ansi.Strip(m.View()))
+	}
+	m = press(m, "1")
+	if got := tableNames(m); !reflect.DeepEqual(got, []string{"web", "Docs", "api"}) {
+		t.Fatalf("sorted by name descending = %v", got)
+	}
+	m = press(m, "3")
+	if got := tableNames(m); !reflect.DeepEqual(got, []string{"Docs", "web", "api"}) {
+		t.Fatalf("sorted by created = %v", got)
+	}
+	// The cursor follows the highlighted bookmark across sorts.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = press(m, "0")
+	if got := tableNames(m); !reflect.DeepEqual(got, []string{"web", "api", "Docs"}) {
+		t.Fatalf("store order = %v", got)
+	}
+	if e, _ := m.(tableModel).selectedEntry(); e.Name != "api" {
+		t.Fatalf("cursor on %q after sort", e.Name)
+	}
+
+	// The filter narrows rows as it is typed; esc clears it.
+	m = press(m, "/", "w", "o", "r")
+	if got := tableNames(m); !reflect.DeepEqual(got, []string{"web", "api"}) {
+		t.Fatalf("filtered = %v", got)
+	}
+	m = press(m, "k")
+	if !strings.Contains(ansi.Strip(m.View()), "/work") {
+		t.Fatalf("filter not shown:\n%s", ansi.Strip(m.View()))
+	}
+	m = press(m, "enter", "*")
+	if got := m.(tableModel).targets(); !reflect.DeepEqual(got, []string{"web", "api"}) {
+		t.Fatalf("marked with filter = %v", got)
+	}
+	m = press(m, "esc")
+	if got := tableNames(m); len(got) != 3 {
+		t.Fatalf("filter not cleared: %v", got)
+	}
+}
+
+func TestTableModel_ResizeAndVisits(t *testing.T) {
+	now := time.Now()
+	visits := bookmarks.Visits{}
+	visits.Record("/src/api", now.Add(-3*24*time.Hour))
+	visits.Record("/src/api", now.Add(-3*24*time.Hour))
+	path, err := bookmarks.DefaultVisitsPath()
+	if err != nil {
+		t.Fatalf("DefaultVisitsPath() error = %v", err)
+	}
+	if err := bookmarks.SaveVisits(path, visits, 0); err != nil {
+		t.Fatalf("SaveVisits() error = %v", err)
+	}
+	t.Cleanup(func() { _ = os.Remove(path) })
+
+	long := "/home/someone/src/github.com/navio/bookmarks/internal/very/deep/api"
+	entries := []bookmarks.Bookmark{
+		{Name: "api", Path: "/src/api"},
+		{Name: "deep", Path: long},
+	}
+	var m tea.Model = newTableModel("", entries, "bm table", mustColumns(t, "name", "path", "visits", "last"))
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 60, Height: 20})
+
+	view := ansi.Strip(m.View())
+	for _, line := range strings.Split(view, "\n") {
+		if ansi.StringWidth(line) > 60 {
+			t.Fatalf("line wider than the terminal: %q", line)
+		}
+	}
+	if !strings.Contains(view, "/home/someo…ry/deep/api") {
+		t.Fatalf("path not middle-truncated:\n%s", view)
+	}
+	if !strings.Contains(view, "3d") || !regexp.MustCompile(`/src/api\s+2\s`).MatchString(view) {
+		t.Fatalf("visit columns missing:\n%s", view)
+	}
+
+	m = press(m, "3", "3")
+	if got := tableNames(m); !reflect.DeepEqual(got, []string{"api", "deep"}) {
+		t.Fatalf("sorted by visits descending = %v", got)
+	}
+}
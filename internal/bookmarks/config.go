@@ -0,0 +1,178 @@
+package bookmarks
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Config holds user settings read from the bm config file.
+type Config struct {
+	// Git treats the store directory as a git repository and commits
+	// every save.
+	Git bool
+	// GitRemote is the remote used by `bm sync` (default "origin").
+	GitRemote string
+	// GitBranch is the branch used by `bm sync` (default: current branch).
+	GitBranch string
+	// TagHooks maps a tag to a shell snippet run after entering any
+	// bookmark with that tag (`hook.tag.<tag> = ...`).
+	TagHooks map[string]string
+	// Rules tag bookmarks automatically (`rule = ...`, repeatable).
+	Rules []Rule
+	// HotDirs is the number of unbookmarked directories `bm record` keeps
+	// visit counts for (`record.hot_dirs`, default 0: disabled).
+	HotDirs int
+	// Finder picks the `bm find` interface: "builtin" (default) or "fzf".
+	Finder string
+	// Keys maps a TUI action to the comma-separated keys that trigger it
+	// (`key.<action> = ...`).
+	Keys map[string]string
+	// Clipboard is how the TUIs and `bm copy` copy paths (`clipboard`):
+	// "auto" (default), "system" or "osc52".
+	Clipboard string
+	// Theme names the TUI color theme (`theme`; empty: auto).
+	Theme string
+	// TableColumns lists the columns `bm table` shows (`table.columns`,
+	// comma-separated; empty: the default set).
+	TableColumns []string
+}
+
+// DefaultConfigPath returns the config file path. BM_CONFIG overrides the
+// default location next to the default store.
+func DefaultConfigPath() (string, error) {
+	if p := strings.TrimSpace(os.Getenv("BM_CONFIG")); p != "" {
+		return p, nil
+	}
+	if xdg := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME")); xdg != "" {
+		return filepath.Join(xdg, "bm", "config"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home dir: %w", err)
+	}
+	return filepath.Join(home, ".config", "bm", "config"), nil
+}
+
+// LoadConfig reads a config file made of `key = value` lines. Missing files
+// return the default config.
+func LoadConfig(path string) (Config, error) {
+	cfg := Config{GitRemote: "origin", Finder: "builtin", Clipboard: "auto"}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") || line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return cfg, fmt.Errorf("%s:%d: expected key = value", path, lineNum)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if err := cfg.set(key, value); err != nil {
+			return cfg, fmt.Errorf("%s:%d: %w", path, lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) set(key, value string) error {
+	switch key {
+	case "git":
+		b, err := parseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		c.Git = b
+	case "git.remote":
+		if value == "" {
+			return fmt.Errorf("%s cannot be empty", key)
+		}
+		c.GitRemote = value
+	case "git.branch":
+		c.GitBranch = value
+	case "record.hot_dirs":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s: expected a non-negative number, got %q", key, value)
+		}
+		c.HotDirs = n
+	case "finder":
+		switch value {
+		case "builtin", "fzf":
+			c.Finder = value
+		default:
+			return fmt.Errorf("%s: expected builtin or fzf, got %q", key, value)
+		}
+	case "clipboard":
+		switch value {
+		case "auto", "system", "osc52":
+			c.Clipboard = value
+		default:
+			return fmt.Errorf("%s: expected auto, system or osc52, got %q", key, value)
+		}
+	case "theme":
+		c.Theme = strings.ToLower(value)
+	case "table.columns":
+		c.TableColumns = nil
+		for _, col := range strings.Split(value, ",") {
+			if col = strings.ToLower(strings.TrimSpace(col)); col != "" {
+				c.TableColumns = append(c.TableColumns, col)
+			}
+		}
+	case "rule":
+		rule, err := ParseRule(value)
+		if err != nil {
+			return err
+		}
+		c.Rules = append(c.Rules, rule)
+	default:
+		if action, ok := strings.CutPrefix(key, "key."); ok && action != "" {
+			if c.Keys == nil {
+				c.Keys = map[string]string{}
+			}
+			c.Keys[action] = value
+			return nil
+		}
+		if tag, ok := strings.CutPrefix(key, "hook.tag."); ok && tag != "" {
+			if c.TagHooks == nil {
+				c.TagHooks = map[string]string{}
+			}
+			c.TagHooks[tag] = value
+			return nil
+		}
+		return fmt.Errorf("unknown key: %s", key)
+	}
+	return nil
+}
+
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return true, nil
+	case "false", "no", "off", "0", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected true or false, got %q", value)
+}
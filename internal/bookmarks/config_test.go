@@ -0,0 +1,58 @@
+package bookmarks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig_MissingFileUsesDefaults(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join(t.TempDir(), "config"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.Git || cfg.GitRemote != "origin" || cfg.Finder != "builtin" || cfg.Clipboard != "auto" {
+		t.Fatalf("LoadConfig() = %#v, want defaults", cfg)
+	}
+}
+
+func TestLoadConfig_ParsesValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	content := "# bm config\n\ngit = true\ngit.remote = backup\n  git.branch = main  \n" +
+		"rule = has go.mod -> lang/go\nrule = under /srv -> srv\nrecord.hot_dirs = 50\nfinder = fzf\n" +
+		"table.columns = Name, path,,visits\nkey.Copy = y\nkey.mark = space,x\ntheme = Light\nclipboard = osc52\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if !cfg.Git || cfg.GitRemote != "backup" || cfg.GitBranch != "main" || cfg.HotDirs != 50 || cfg.Finder != "fzf" {
+		t.Fatalf("LoadConfig() = %#v", cfg)
+	}
+	if len(cfg.Rules) != 2 || cfg.Rules[0].Kind != "has" || cfg.Rules[1].Arg != "/srv" {
+		t.Fatalf("LoadConfig().Rules = %#v", cfg.Rules)
+	}
+	if !reflect.DeepEqual(cfg.TableColumns, []string{"name", "path", "visits"}) {
+		t.Fatalf("LoadConfig().TableColumns = %#v", cfg.TableColumns)
+	}
+	if cfg.Theme != "light" || cfg.Clipboard != "osc52" {
+		t.Fatalf("LoadConfig() = %#v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Keys, map[string]string{"copy": "y", "mark": "space,x"}) {
+		t.Fatalf("LoadConfig().Keys = %#v", cfg.Keys)
+	}
+}
+
+func TestLoadConfig_RejectsUnknownKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("colour = red\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatalf("expected unknown key error")
+	}
+}
@@ -0,0 +1,227 @@
+package bookmarks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SyncResult reports what `Sync` did.
+type SyncResult struct {
+	Pulled    bool
+	Pushed    bool
+	Merged    bool
+	Conflicts []Conflict
+}
+
+// GitCommit commits the store file in its git repository if it changed.
+// Other files in the repository are left untouched.
+func GitCommit(storePath, message string) error {
+	repo, rel, err := gitLocate(storePath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(storePath); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if _, err := runGit(repo, "add", "--", rel); err != nil {
+		return err
+	}
+	changed, err := gitHasStagedChanges(repo, rel)
+	if err != nil || !changed {
+		return err
+	}
+	_, err = runGit(repo, "commit", "-q", "-m", message, "--", rel)
+	return err
+}
+
+// Sync commits pending store changes, pulls from remote/branch, merges the
+// store file record by record when histories diverged, and pushes the result.
+// An empty branch means the current branch.
+func Sync(storePath, remote, branch string) (SyncResult, error) {
+	result := SyncResult{}
+	repo, rel, err := gitLocate(storePath)
+	if err != nil {
+		return result, err
+	}
+	if err := GitCommit(storePath, "sync local changes"); err != nil {
+		return result, err
+	}
+	if branch == "" {
+		branch, err = runGit(repo, "rev-parse", "--abbrev-ref", "HEAD")
+		if err != nil {
+			return result, err
+		}
+	}
+
+	heads, err := runGit(repo, "ls-remote", "--heads", remote, branch)
+	if err != nil {
+		return result, err
+	}
+	if heads != "" {
+		if _, err := runGit(repo, "fetch", "-q", remote, branch); err != nil {
+			return result, err
+		}
+		theirs, err := runGit(repo, "rev-parse", "FETCH_HEAD")
+		if err != nil {
+			return result, err
+		}
+		if err := gitIntegrate(repo, rel, theirs, &result); err != nil {
+			return result, err
+		}
+	}
+
+	ahead := true
+	if heads != "" {
+		count, err := runGit(repo, "rev-list", "--count", "FETCH_HEAD..HEAD")
+		if err != nil {
+			return result, err
+		}
+		ahead = count != "0"
+	}
+	if ahead {
+		if _, err := runGit(repo, "push", "-q", remote, "HEAD:refs/heads/"+branch); err != nil {
+			return result, err
+		}
+		result.Pushed = true
+	}
+	return result, nil
+}
+
+func gitIntegrate(repo, rel, theirs string, result *SyncResult) error {
+	if _, err := runGit(repo, "rev-parse", "--verify", "-q", "HEAD"); err != nil {
+		// Nothing committed locally yet: adopt the remote history.
+		if _, err := runGit(repo, "reset", "-q", "--hard", theirs); err != nil {
+			return err
+		}
+		result.Pulled = true
+		return nil
+	}
+	if gitIsAncestor(repo, theirs, "HEAD") {
+		return nil
+	}
+	if gitIsAncestor(repo, "HEAD", theirs) {
+		if _, err := runGit(repo, "merge", "-q", "--ff-only", theirs); err != nil {
+			return err
+		}
+		result.Pulled = true
+		return nil
+	}
+
+	base, err := runGit(repo, "merge-base", "HEAD", theirs)
+	if err != nil {
+		return err
+	}
+	baseEntries, err := gitReadStore(repo, base, rel)
+	if err != nil {
+		return err
+	}
+	oursEntries, err := gitReadStore(repo, "HEAD", rel)
+	if err != nil {
+		return err
+	}
+	theirsEntries, err := gitReadStore(repo, theirs, rel)
+	if err != nil {
+		return err
+	}
+	merged, conflicts := Merge(baseEntries, oursEntries, theirsEntries)
+
+	// Let git merge the rest of the repository; the store file is
+	// overwritten with the record-level result below. A failed merge is only
+	// expected when it left conflicts to resolve.
+	if _, mergeErr := runGit(repo, "merge", "-q", "--no-ff", "--no-commit", theirs); mergeErr != nil {
+		unmerged, err := runGit(repo, "diff", "--name-only", "--diff-filter=U")
+		if err != nil || unmerged == "" {
+			_, _ = runGit(repo, "merge", "--abort")
+			return mergeErr
+		}
+	}
+	if err := Save(filepath.Join(repo, rel), merged); err != nil {
+		_, _ = runGit(repo, "merge", "--abort")
+		return err
+	}
+	if _, err := runGit(repo, "add", "--", rel); err != nil {
+		return err
+	}
+	unmerged, err := runGit(repo, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return err
+	}
+	if unmerged != "" {
+		_, _ = runGit(repo, "merge", "--abort")
+		return fmt.Errorf("merge conflicts outside the store file:\n%s", unmerged)
+	}
+	if _, err := runGit(repo, "commit", "-q", "--no-edit", "-m", "merge remote bookmarks"); err != nil {
+		return err
+	}
+	result.Pulled = true
+	result.Merged = true
+	result.Conflicts = conflicts
+	return nil
+}
+
+// gitLocate returns the repository root and the store path relative to it.
+func gitLocate(storePath string) (string, string, error) {
+	dir := filepath.Dir(storePath)
+	top, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", "", fmt.Errorf("store directory is not a git repository: %s", dir)
+	}
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", "", err
+	}
+	rel, err := filepath.Rel(top, filepath.Join(realDir, filepath.Base(storePath)))
+	if err != nil {
+		return "", "", err
+	}
+	return top, filepath.ToSlash(rel), nil
+}
+
+func gitReadStore(repo, rev, rel string) ([]Bookmark, error) {
+	if _, err := runGit(repo, "cat-file", "-e", rev+":"+rel); err != nil {
+		return []Bookmark{}, nil
+	}
+	content, err := runGit(repo, "show", rev+":"+rel)
+	if err != nil {
+		return nil, err
+	}
+	return Read(strings.NewReader(content))
+}
+
+func gitHasStagedChanges(repo, rel string) (bool, error) {
+	cmd := exec.Command("git", "-C", repo, "diff", "--cached", "--quiet", "--", rel)
+	err := cmd.Run()
+	if err == nil {
+		return false, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	return false, err
+}
+
+func gitIsAncestor(repo, ancestor, rev string) bool {
+	_, err := runGit(repo, "merge-base", "--is-ancestor", ancestor, rev)
+	return err == nil
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,187 @@
+package bookmarks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func requireGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "bm test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "bm@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "bm test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "bm@example.com")
+}
+
+func mustGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := runGit(dir, args...)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return out
+}
+
+func TestGitCommit_CommitsOnlyStoreChanges(t *testing.T) {
+	requireGit(t)
+	dir := t.TempDir()
+	mustGit(t, dir, "init", "-q", "-b", "main")
+	storePath := filepath.Join(dir, "bookmarks.tsv")
+
+	entries := []Bookmark{{Name: "proj", Path: "/tmp/proj", CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}}
+	if err := Save(storePath, entries); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := GitCommit(storePath, "add proj"); err != nil {
+		t.Fatalf("GitCommit() error = %v", err)
+	}
+	// A second commit without changes is a no-op.
+	if err := GitCommit(storePath, "noop"); err != nil {
+		t.Fatalf("GitCommit() error = %v", err)
+	}
+
+	log := mustGit(t, dir, "log", "--format=%s")
+	if log != "add proj" {
+		t.Fatalf("git log = %q, want %q", log, "add proj")
+	}
+}
+
+func TestSync_MergesDivergedClones(t *testing.T) {
+	requireGit(t)
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	mustGit(t, root, "init", "-q", "--bare", "-b", "main", remote)
+
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	laptopA := filepath.Join(root, "a")
+	laptopB := filepath.Join(root, "b")
+	for _, dir := range []string{laptopA, laptopB} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		mustGit(t, dir, "init", "-q", "-b", "main")
+		mustGit(t, dir, "remote", "add", "origin", remote)
+	}
+	storeA := filepath.Join(laptopA, "bookmarks.tsv")
+	storeB := filepath.Join(laptopB, "bookmarks.tsv")
+
+	shared := []Bookmark{{Name: "shared", Path: "/tmp/shared", CreatedAt: created}}
+	if err := Save(storeA, shared); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := GitCommit(storeA, "add shared"); err != nil {
+		t.Fatalf("GitCommit() error = %v", err)
+	}
+	if res, err := Sync(storeA, "origin", ""); err != nil || !res.Pushed {
+		t.Fatalf("Sync(a) = %#v, %v; want pushed", res, err)
+	}
+	if res, err := Sync(storeB, "origin", "main"); err != nil || !res.Pulled {
+		t.Fatalf("Sync(b) = %#v, %v; want pulled", res, err)
+	}
+
+	// Both laptops add a different bookmark; the lines conflict for git.
+	a := append(append([]Bookmark{}, shared...), Bookmark{Name: "from-a", Path: "/tmp/a", CreatedAt: created})
+	if err := Save(storeA, a); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := GitCommit(storeA, "add from-a"); err != nil {
+		t.Fatalf("GitCommit() error = %v", err)
+	}
+	b := append([]Bookmark{{Name: "from-b", Path: "/tmp/b", CreatedAt: created}}, shared...)
+	if err := Save(storeB, b); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if _, err := Sync(storeA, "origin", ""); err != nil {
+		t.Fatalf("Sync(a) error = %v", err)
+	}
+	res, err := Sync(storeB, "origin", "")
+	if err != nil {
+		t.Fatalf("Sync(b) error = %v", err)
+	}
+	if !res.Merged || !res.Pushed || len(res.Conflicts) != 0 {
+		t.Fatalf("Sync(b) = %#v, want merged and pushed without conflicts", res)
+	}
+	if _, err := Sync(storeA, "origin", ""); err != nil {
+		t.Fatalf("Sync(a) error = %v", err)
+	}
+
+	for _, store := range []string{storeA, storeB} {
+		entries, err := Load(store)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		names := []string{}
+		for _, e := range entries {
+			names = append(names, e.Name)
+		}
+		got := strings.Join(names, ",")
+		if got != "from-b,shared,from-a" {
+			t.Fatalf("%s names = %s, want from-b,shared,from-a", store, got)
+		}
+	}
+}
+
+func TestSync_FailedMergeIsNotCommitted(t *testing.T) {
+	requireGit(t)
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	mustGit(t, root, "init", "-q", "--bare", "-b", "main", remote)
+
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	laptopA := filepath.Join(root, "a")
+	mustGit(t, root, "init", "-q", "-b", "main", laptopA)
+	mustGit(t, laptopA, "remote", "add", "origin", remote)
+	storeA := filepath.Join(laptopA, "bookmarks.tsv")
+	if err := Save(storeA, []Bookmark{{Name: "shared", Path: "/tmp/shared", CreatedAt: created}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := GitCommit(storeA, "add shared"); err != nil {
+		t.Fatalf("GitCommit() error = %v", err)
+	}
+	if _, err := Sync(storeA, "origin", ""); err != nil {
+		t.Fatalf("Sync(a) error = %v", err)
+	}
+	laptopB := filepath.Join(root, "b")
+	mustGit(t, root, "clone", "-q", remote, laptopB)
+	storeB := filepath.Join(laptopB, "bookmarks.tsv")
+
+	// A commits a new file and a bookmark; B has the same file uncommitted,
+	// which git refuses to overwrite without reporting a conflict.
+	if err := os.WriteFile(filepath.Join(laptopA, "notes.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mustGit(t, laptopA, "add", "notes.txt")
+	mustGit(t, laptopA, "commit", "-q", "-m", "notes")
+	if err := Save(storeA, []Bookmark{{Name: "shared", Path: "/tmp/shared", CreatedAt: created}, {Name: "from-a", Path: "/tmp/a", CreatedAt: created}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := Sync(storeA, "origin", ""); err != nil {
+		t.Fatalf("Sync(a) error = %v", err)
+	}
+	if err := Save(storeB, []Bookmark{{Name: "from-b", Path: "/tmp/b", CreatedAt: created}, {Name: "shared", Path: "/tmp/shared", CreatedAt: created}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(laptopB, "notes.txt"), []byte("b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Sync(storeB, "origin", ""); err == nil {
+		t.Fatalf("Sync(b) succeeded over an untracked file the merge would overwrite")
+	}
+	if log := mustGit(t, laptopB, "log", "-1", "--format=%s"); log != "sync local changes" {
+		t.Fatalf("last commit in b = %q, want the local sync commit", log)
+	}
+	if _, err := runGit(laptopB, "rev-parse", "-q", "--verify", "MERGE_HEAD"); err == nil {
+		t.Fatalf("merge left in progress")
+	}
+}
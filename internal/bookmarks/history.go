@@ -0,0 +1,295 @@
+package bookmarks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Suggestion is a directory frequently changed into in shell history.
+type Suggestion struct {
+	Name  string
+	Path  string
+	Tags  []string
+	Count int
+}
+
+var (
+	bashTimestamp = regexp.MustCompile(`^#\d+$`)
+	zshExtended   = regexp.MustCompile(`^: \d+:\d+;`)
+)
+
+// historySniffLines is how many leading lines HistoryFormat inspects.
+const historySniffLines = 10
+
+// HistoryFormat returns the format of a history file, read from r without
+// consuming it: "zsh" when a line starts with an extended zsh timestamp
+// (": 1700000000:0;"), "fish" for fish's "- cmd:" entries and "bash" for
+// bash "#1700000000" timestamps. Files without these markers, such as plain
+// zsh histories, fall back to the file name: "fish" for fish_history, "zsh"
+// for files mentioning zsh, and "bash" otherwise.
+func HistoryFormat(path string, r *bufio.Reader) string {
+	head, _ := r.Peek(4096)
+	lines := strings.Split(string(head), "\n")
+	if len(lines) > historySniffLines {
+		lines = lines[:historySniffLines]
+	}
+	for _, line := range lines {
+		switch {
+		case zshExtended.MatchString(line):
+			return "zsh"
+		case strings.HasPrefix(line, "- cmd:"):
+			return "fish"
+		case bashTimestamp.MatchString(line):
+			return "bash"
+		}
+	}
+
+	base := filepath.Base(path)
+	switch {
+	case strings.Contains(base, "fish"):
+		return "fish"
+	case strings.Contains(base, "zsh"):
+		return "zsh"
+	}
+	return "bash"
+}
+
+// ReadHistory returns the commands recorded in a bash, zsh (plain or
+// extended) or fish history file, oldest first.
+func ReadHistory(r io.Reader, format string) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var commands []string
+	var pending string
+	continued := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch format {
+		case "fish":
+			if cmd, ok := strings.CutPrefix(line, "- cmd: "); ok {
+				commands = append(commands, unescapeFish(cmd))
+			}
+			continue
+		case "bash":
+			if bashTimestamp.MatchString(line) {
+				continue
+			}
+			commands = append(commands, line)
+			continue
+		case "zsh":
+		default:
+			return nil, fmt.Errorf("unknown history format: %s", format)
+		}
+
+		// zsh writes multi-line commands with a trailing backslash on each
+		// continued line.
+		if !continued {
+			line = zshExtended.ReplaceAllString(line, "")
+			pending = ""
+		}
+		if strings.HasSuffix(line, "\\") {
+			pending += strings.TrimSuffix(line, "\\") + "\n"
+			continued = true
+			continue
+		}
+		commands = append(commands, pending+line)
+		continued = false
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+func unescapeFish(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case 'n':
+				b.WriteByte('\n')
+				i++
+				continue
+			case '\\':
+				b.WriteByte('\\')
+				i++
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// CdTargets counts the directories changed into by cd and pushd in commands.
+// Relative targets are resolved against the last absolute directory seen, so
+// `cd ~/src` followed by `cd api` counts ~/src/api; relative targets with no
+// known starting point are ignored.
+func CdTargets(commands []string, home string) map[string]int {
+	counts := map[string]int{}
+	cwd := ""
+	for _, command := range commands {
+		for _, part := range splitCommands(command) {
+			target, ok := cdArgument(part)
+			if !ok {
+				continue
+			}
+			switch {
+			case target == "" || target == "~":
+				cwd = home
+				continue
+			case target == "-":
+				cwd = ""
+				continue
+			case strings.HasPrefix(target, "~/"):
+				target = filepath.Join(home, target[2:])
+			case filepath.IsAbs(target):
+			case cwd != "":
+				target = filepath.Join(cwd, target)
+			default:
+				continue
+			}
+			cwd = filepath.Clean(target)
+			counts[cwd]++
+		}
+	}
+	return counts
+}
+
+// splitCommands splits a command line on ;, &&, || and | outside quotes.
+func splitCommands(line string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '\\':
+			i++
+		case c == ';' || c == '|' || c == '&' || c == '\n':
+			parts = append(parts, line[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, line[start:])
+}
+
+// cdArgument returns the directory argument of a cd or pushd command. It
+// reports false for other commands and for arguments using expansions it
+// cannot evaluate.
+func cdArgument(command string) (string, bool) {
+	words := shellWords(strings.TrimSpace(command))
+	if len(words) > 0 && words[0] == "builtin" {
+		words = words[1:]
+	}
+	if len(words) == 0 || (words[0] != "cd" && words[0] != "pushd") {
+		return "", false
+	}
+	var target string
+	for _, w := range words[1:] {
+		if w == "--" || (strings.HasPrefix(w, "-") && w != "-") {
+			continue
+		}
+		target = w
+	}
+	if strings.ContainsAny(target, "$`*?{") {
+		return "", false
+	}
+	return target, true
+}
+
+// shellWords splits s into words, removing quotes and backslash escapes.
+func shellWords(s string) []string {
+	var words []string
+	var b strings.Builder
+	inWord := false
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				b.WriteByte(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+		case c == '\\' && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+			inWord = true
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, b.String())
+				b.Reset()
+				inWord = false
+			}
+		default:
+			b.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, b.String())
+	}
+	return words
+}
+
+// Suggest turns cd target counts into up to limit suggestions, most visited
+// first. Directories that no longer exist, are already bookmarked, or are
+// the home directory or filesystem root are skipped. Proposed names are unique
+// among existing names and each other.
+func Suggest(counts map[string]int, existing []Bookmark, home string, limit int) []Suggestion {
+	bookmarked := map[string]bool{}
+	names := map[string]bool{}
+	for _, e := range existing {
+		bookmarked[filepath.Clean(e.Path)] = true
+		names[e.Name] = true
+	}
+
+	paths := make([]string, 0, len(counts))
+	for p := range counts {
+		paths = append(paths, p)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if counts[paths[i]] != counts[paths[j]] {
+			return counts[paths[i]] > counts[paths[j]]
+		}
+		return paths[i] < paths[j]
+	})
+
+	var suggestions []Suggestion
+	for _, p := range paths {
+		if limit > 0 && len(suggestions) >= limit {
+			break
+		}
+		if bookmarked[p] || p == home || p == string(filepath.Separator) {
+			continue
+		}
+		if info, err := os.Stat(p); err != nil || !info.IsDir() {
+			continue
+		}
+		suggestions = append(suggestions, Suggestion{
+			Name:  uniqueName(p, names),
+			Path:  p,
+			Tags:  DetectTags(p),
+			Count: counts[p],
+		})
+	}
+	return suggestions
+}
@@ -0,0 +1,133 @@
+package bookmarks
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadHistory(t *testing.T) {
+	tests := []struct {
+		format string
+		input  string
+		want   []string
+	}{
+		{
+			format: "bash",
+			input:  "#1700000000\ncd ~/src\nls -la\n",
+			want:   []string{"cd ~/src", "ls -la"},
+		},
+		{
+			format: "zsh",
+			input:  ": 1700000000:0;cd /srv/app\n: 1700000001:0;echo one\\\ntwo\nplain\n",
+			want:   []string{"cd /srv/app", "echo one\ntwo", "plain"},
+		},
+		{
+			format: "fish",
+			input:  "- cmd: cd ~/src/api\n  when: 1700000000\n- cmd: echo a\\\\nb\\nc\n  when: 1700000001\n  paths:\n    - ~/src\n",
+			want:   []string{"cd ~/src/api", "echo a\\nb\nc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := ReadHistory(strings.NewReader(tt.input), tt.format)
+			if err != nil {
+				t.Fatalf("ReadHistory() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ReadHistory() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryFormat(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		want    string
+	}{
+		{path: "/home/me/.bash_history", content: "cd ~/src\n", want: "bash"},
+		{path: "/home/me/.zsh_history", content: "cd ~/src\n", want: "zsh"},
+		{path: "/home/me/.local/share/fish/fish_history", content: "", want: "fish"},
+		{path: "/home/me/.histfile", content: "cd ~/src\n", want: "bash"},
+		// Content wins over the name.
+		{path: "/home/me/.histfile", content: ": 1700000000:0;cd /srv/app\n", want: "zsh"},
+		{path: "/home/me/.histfile", content: "- cmd: cd ~/src\n  when: 1700000000\n", want: "fish"},
+		{path: "/home/me/.zsh_history", content: "#1700000000\ncd ~/src\n", want: "bash"},
+	}
+	for _, tt := range tests {
+		r := bufio.NewReader(strings.NewReader(tt.content))
+		if got := HistoryFormat(tt.path, r); got != tt.want {
+			t.Errorf("HistoryFormat(%q, %q) = %q, want %q", tt.path, tt.content, got, tt.want)
+		}
+		if rest, _ := io.ReadAll(r); string(rest) != tt.content {
+			t.Errorf("HistoryFormat(%q) consumed input, left %q", tt.path, rest)
+		}
+	}
+}
+
+func TestCdTargets(t *testing.T) {
+	commands := []string{
+		"cd ~/src",
+		"cd api && make test",
+		"git status; cd ..",
+		"cd 'My Projects'",
+		"pushd /srv/app",
+		"cd -",
+		"cd relative",
+		"cd $GOPATH",
+		"builtin cd -P /srv/app",
+		"echo cd /nope",
+		"cd",
+		"cd src",
+	}
+	got := CdTargets(commands, "/home/me")
+	want := map[string]int{
+		"/home/me/src":             3,
+		"/home/me/src/api":         1,
+		"/home/me/src/My Projects": 1,
+		"/srv/app":                 2,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("CdTargets() = %#v, want %#v", got, want)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	home := t.TempDir()
+	api := filepath.Join(home, "src", "api")
+	web := filepath.Join(home, "src", "web")
+	other := filepath.Join(home, "other", "api")
+	for _, dir := range []string{api, web, other} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(api, "go.mod"), nil, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	counts := map[string]int{
+		api:                          5,
+		web:                          9,
+		other:                        2,
+		home:                         20,
+		filepath.Join(home, "gone"):  7,
+		filepath.Join(home, "other"): 1,
+	}
+	existing := []Bookmark{{Name: "web", Path: web}}
+
+	got := Suggest(counts, existing, home, 2)
+	want := []Suggestion{
+		{Name: "api", Path: api, Tags: []string{"lang/go"}, Count: 5},
+		{Name: "other-api", Path: other, Count: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Suggest() = %#v, want %#v", got, want)
+	}
+}
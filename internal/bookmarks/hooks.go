@@ -0,0 +1,113 @@
+package bookmarks
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Hook is a shell snippet that runs after entering a bookmark.
+type Hook struct {
+	// Source is "bookmark" for the bookmark's own hook or "tag:<tag>".
+	Source string
+	Script string
+}
+
+// HooksFor returns the hooks that apply to a bookmark: its own hook first,
+// then tag hooks in the bookmark's tag order.
+func HooksFor(entry Bookmark, tagHooks map[string]string) []Hook {
+	var hooks []Hook
+	if strings.TrimSpace(entry.Hook) != "" {
+		hooks = append(hooks, Hook{Source: "bookmark", Script: entry.Hook})
+	}
+	for _, tag := range entry.Tags {
+		script := tagHooks[strings.ToLower(tag)]
+		if strings.TrimSpace(script) == "" {
+			continue
+		}
+		hooks = append(hooks, Hook{Source: "tag:" + tag, Script: script})
+	}
+	return hooks
+}
+
+// StateDir returns the per-machine state directory, which is never part of a
+// synced store.
+func StateDir() (string, error) {
+	if xdg := strings.TrimSpace(os.Getenv("XDG_STATE_HOME")); xdg != "" {
+		return filepath.Join(xdg, "bm"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home dir: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "bm"), nil
+}
+
+// DefaultTrustPath returns the location of the hook allow-list.
+func DefaultTrustPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "trusted"), nil
+}
+
+// HookDigest identifies a hook script running in a directory. Changing either
+// the script or the directory requires trusting it again.
+func HookDigest(dir, script string) string {
+	sum := sha256.Sum256([]byte(dir + "\x00" + script))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadTrust reads the set of trusted hook digests. Missing files return an
+// empty set.
+func LoadTrust(path string) (map[string]bool, error) {
+	trusted := map[string]bool{}
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return trusted, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		trusted[line] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return trusted, nil
+}
+
+// SaveTrust writes the set of trusted hook digests.
+func SaveTrust(path string, trusted map[string]bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	digests := make([]string, 0, len(trusted))
+	for digest, ok := range trusted {
+		if ok {
+			digests = append(digests, digest)
+		}
+	}
+	sort.Strings(digests)
+	var b strings.Builder
+	for _, digest := range digests {
+		b.WriteString(digest + "\n")
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o600)
+}
@@ -0,0 +1,44 @@
+package bookmarks
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHooksFor_BookmarkThenTags(t *testing.T) {
+	entry := Bookmark{Name: "svc", Path: "/tmp/svc", Tags: []string{"node", "k8s", "go"}, Hook: "echo hi"}
+	tagHooks := map[string]string{
+		"k8s":  "export KUBECONFIG=~/.kube/dev",
+		"node": "nvm use",
+	}
+
+	got := HooksFor(entry, tagHooks)
+	want := []Hook{
+		{Source: "bookmark", Script: "echo hi"},
+		{Source: "tag:node", Script: "nvm use"},
+		{Source: "tag:k8s", Script: "export KUBECONFIG=~/.kube/dev"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("HooksFor() = %#v, want %#v", got, want)
+	}
+}
+
+func TestTrust_RoundTripAndDigest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "trusted")
+	digest := HookDigest("/tmp/svc", "nvm use")
+	if digest == HookDigest("/tmp/other", "nvm use") || digest == HookDigest("/tmp/svc", "nvm use 20") {
+		t.Fatalf("HookDigest() must depend on both directory and script")
+	}
+
+	if err := SaveTrust(path, map[string]bool{digest: true}); err != nil {
+		t.Fatalf("SaveTrust() error = %v", err)
+	}
+	got, err := LoadTrust(path)
+	if err != nil {
+		t.Fatalf("LoadTrust() error = %v", err)
+	}
+	if !got[digest] || len(got) != 1 {
+		t.Fatalf("LoadTrust() = %#v", got)
+	}
+}
@@ -0,0 +1,182 @@
+package bookmarks
+
+import (
+	"reflect"
+)
+
+// Conflict describes a bookmark field that could not be merged
+// automatically. The merged result keeps the ours value.
+type Conflict struct {
+	Name   string `json:"name"`
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+	Ours   string `json:"ours"`
+	Theirs string `json:"theirs"`
+}
+
+// Merge performs a record-level three-way merge keyed on bookmark name.
+//
+// Records changed on only one side take that side's version. When both sides
+// changed a record, fields are merged individually: tags added on either side
+// are kept and tags removed on either side are dropped, text fields (path,
+// description, notes, hook, repo) from the side with the newest UpdatedAt
+// win, and the earliest CreatedAt is kept. Fields changed on both sides
+// without distinguishing timestamps, and records deleted on one side but
+// changed on the other, are reported as conflicts.
+//
+// The result follows the order of ours, with records added by theirs
+// appended in their order.
+func Merge(base, ours, theirs []Bookmark) ([]Bookmark, []Conflict) {
+	baseByName := indexByName(base)
+	oursByName := indexByName(ours)
+	theirsByName := indexByName(theirs)
+
+	order := make([]string, 0, len(ours)+len(theirs))
+	seen := map[string]struct{}{}
+	for _, list := range [][]Bookmark{ours, theirs, base} {
+		for _, entry := range list {
+			if _, ok := seen[entry.Name]; ok {
+				continue
+			}
+			seen[entry.Name] = struct{}{}
+			order = append(order, entry.Name)
+		}
+	}
+
+	merged := make([]Bookmark, 0, len(order))
+	var conflicts []Conflict
+	for _, name := range order {
+		result, recordConflicts := mergeRecord(baseByName[name], oursByName[name], theirsByName[name])
+		conflicts = append(conflicts, recordConflicts...)
+		if result != nil {
+			merged = append(merged, *result)
+		}
+	}
+	return merged, conflicts
+}
+
+func mergeRecord(base, ours, theirs *Bookmark) (*Bookmark, []Conflict) {
+	switch {
+	case ours == nil && theirs == nil:
+		return nil, nil
+	case sameRecord(ours, theirs):
+		return mergeTimestamps(ours, theirs), nil
+	case sameRecord(base, ours):
+		return theirs, nil
+	case sameRecord(base, theirs):
+		return ours, nil
+	case ours == nil:
+		return theirs, []Conflict{{
+			Name:   theirs.Name,
+			Field:  "record",
+			Reason: "deleted in ours, changed in theirs",
+			Theirs: theirs.Path,
+		}}
+	case theirs == nil:
+		return ours, []Conflict{{
+			Name:   ours.Name,
+			Field:  "record",
+			Reason: "changed in ours, deleted in theirs",
+			Ours:   ours.Path,
+		}}
+	}
+
+	if base == nil {
+		base = &Bookmark{Name: ours.Name}
+	}
+	result := mergeTimestamps(ours, theirs)
+	result.Tags = mergeTags(base.Tags, ours.Tags, theirs.Tags)
+
+	theirsNewer := theirs.UpdatedAt.After(ours.UpdatedAt)
+	oursNewer := ours.UpdatedAt.After(theirs.UpdatedAt)
+
+	var conflicts []Conflict
+	fields := []struct {
+		name               string
+		result             *string
+		base, ours, theirs string
+	}{
+		{"path", &result.Path, base.Path, ours.Path, theirs.Path},
+		{"description", &result.Description, base.Description, ours.Description, theirs.Description},
+		{"notes", &result.Notes, base.Notes, ours.Notes, theirs.Notes},
+		{"hook", &result.Hook, base.Hook, ours.Hook, theirs.Hook},
+		{"repo", &result.Repo, base.Repo, ours.Repo, theirs.Repo},
+		{"repo_path", &result.RepoPath, base.RepoPath, ours.RepoPath, theirs.RepoPath},
+	}
+	for _, f := range fields {
+		switch {
+		case f.ours == f.theirs || f.theirs == f.base:
+			*f.result = f.ours
+		case f.ours == f.base:
+			*f.result = f.theirs
+		case theirsNewer:
+			*f.result = f.theirs
+		case oursNewer:
+			*f.result = f.ours
+		default:
+			*f.result = f.ours
+			conflicts = append(conflicts, Conflict{
+				Name:   ours.Name,
+				Field:  f.name,
+				Reason: "changed on both sides",
+				Ours:   f.ours,
+				Theirs: f.theirs,
+			})
+		}
+	}
+	return result, conflicts
+}
+
+// mergeTimestamps returns a copy of ours with the earliest CreatedAt and the
+// latest UpdatedAt of both sides.
+func mergeTimestamps(ours, theirs *Bookmark) *Bookmark {
+	result := *ours
+	if !theirs.CreatedAt.IsZero() && (result.CreatedAt.IsZero() || theirs.CreatedAt.Before(result.CreatedAt)) {
+		result.CreatedAt = theirs.CreatedAt
+	}
+	if theirs.UpdatedAt.After(result.UpdatedAt) {
+		result.UpdatedAt = theirs.UpdatedAt
+	}
+	return &result
+}
+
+// mergeTags keeps tags present on both sides plus tags added by either side,
+// in ours order followed by theirs.
+func mergeTags(base, ours, theirs []string) []string {
+	var result []string
+	for _, tag := range ours {
+		if ContainsTag(theirs, tag) || !ContainsTag(base, tag) {
+			result = append(result, tag)
+		}
+	}
+	for _, tag := range theirs {
+		if !ContainsTag(ours, tag) && !ContainsTag(base, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// sameRecord compares the user-visible fields of two records. Timestamps are
+// ignored because they differ between machines for otherwise equal entries.
+func sameRecord(a, b *Bookmark) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Name == b.Name &&
+		a.Path == b.Path &&
+		a.Description == b.Description &&
+		a.Notes == b.Notes &&
+		a.Hook == b.Hook &&
+		a.Repo == b.Repo &&
+		a.RepoPath == b.RepoPath &&
+		reflect.DeepEqual(normalizeTags(tagsToString(a.Tags)), normalizeTags(tagsToString(b.Tags)))
+}
+
+func indexByName(entries []Bookmark) map[string]*Bookmark {
+	index := make(map[string]*Bookmark, len(entries))
+	for i := range entries {
+		index[entries[i].Name] = &entries[i]
+	}
+	return index
+}
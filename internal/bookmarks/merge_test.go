@@ -0,0 +1,114 @@
+package bookmarks
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMerge_TakesOneSidedChanges(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	base := []Bookmark{
+		{Name: "a", Path: "/tmp/a", CreatedAt: created},
+		{Name: "b", Path: "/tmp/b", CreatedAt: created},
+		{Name: "c", Path: "/tmp/c", CreatedAt: created},
+	}
+	ours := []Bookmark{
+		{Name: "a", Path: "/tmp/a2", CreatedAt: created},
+		{Name: "b", Path: "/tmp/b", CreatedAt: created},
+		{Name: "c", Path: "/tmp/c", CreatedAt: created},
+		{Name: "d", Path: "/tmp/d", CreatedAt: created},
+	}
+	// theirs reorders, retags b and deletes c.
+	theirs := []Bookmark{
+		{Name: "b", Path: "/tmp/b", Tags: []string{"work"}, CreatedAt: created},
+		{Name: "a", Path: "/tmp/a", CreatedAt: created},
+		{Name: "e", Path: "/tmp/e", CreatedAt: created},
+	}
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Fatalf("conflicts = %#v, want none", conflicts)
+	}
+	got := map[string]Bookmark{}
+	names := []string{}
+	for _, entry := range merged {
+		got[entry.Name] = entry
+		names = append(names, entry.Name)
+	}
+	if want := "a,b,d,e"; strings.Join(names, ",") != want {
+		t.Fatalf("merged names = %s, want %s", strings.Join(names, ","), want)
+	}
+	if got["a"].Path != "/tmp/a2" {
+		t.Fatalf("a.Path = %q, want ours", got["a"].Path)
+	}
+	if !ContainsTag(got["b"].Tags, "work") {
+		t.Fatalf("b.Tags = %#v, want theirs", got["b"].Tags)
+	}
+}
+
+func TestMerge_ReportsBothSidedChanges(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	base := []Bookmark{{Name: "a", Path: "/tmp/a", CreatedAt: created}}
+	ours := []Bookmark{{Name: "a", Path: "/tmp/ours", CreatedAt: created}}
+	theirs := []Bookmark{{Name: "a", Path: "/tmp/theirs", CreatedAt: created}}
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 1 || conflicts[0].Name != "a" {
+		t.Fatalf("conflicts = %#v, want one for a", conflicts)
+	}
+	if len(merged) != 1 || merged[0].Path != "/tmp/ours" {
+		t.Fatalf("merged = %#v, want ours kept", merged)
+	}
+}
+
+func TestMerge_BothSidedChangesMergeFields(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	base := []Bookmark{{Name: "a", Path: "/tmp/a", Tags: []string{"old", "keep"}, CreatedAt: created}}
+	ours := []Bookmark{{
+		Name:      "a",
+		Path:      "/tmp/ours",
+		Tags:      []string{"keep", "mine"},
+		CreatedAt: created,
+		UpdatedAt: created.Add(time.Hour),
+	}}
+	theirs := []Bookmark{{
+		Name:      "a",
+		Path:      "/tmp/theirs",
+		Tags:      []string{"old", "keep", "yours"},
+		CreatedAt: created.Add(-time.Minute),
+		UpdatedAt: created.Add(2 * time.Hour),
+	}}
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Fatalf("conflicts = %#v, want none", conflicts)
+	}
+	if len(merged) != 1 {
+		t.Fatalf("merged = %#v, want one record", merged)
+	}
+	got := merged[0]
+	if got.Path != "/tmp/theirs" {
+		t.Fatalf("Path = %q, want newest change", got.Path)
+	}
+	if want := "keep,mine,yours"; strings.Join(got.Tags, ",") != want {
+		t.Fatalf("Tags = %v, want %s", got.Tags, want)
+	}
+	if !got.CreatedAt.Equal(created.Add(-time.Minute)) || !got.UpdatedAt.Equal(created.Add(2*time.Hour)) {
+		t.Fatalf("timestamps = %v/%v, want earliest created and latest updated", got.CreatedAt, got.UpdatedAt)
+	}
+}
+
+func TestMerge_DeletedAndChangedIsConflict(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	base := []Bookmark{{Name: "a", Path: "/tmp/a", CreatedAt: created}}
+	theirs := []Bookmark{{Name: "a", Path: "/tmp/moved", CreatedAt: created}}
+
+	merged, conflicts := Merge(base, nil, theirs)
+	if len(conflicts) != 1 || conflicts[0].Field != "record" {
+		t.Fatalf("conflicts = %#v, want record conflict", conflicts)
+	}
+	if len(merged) != 1 || merged[0].Path != "/tmp/moved" {
+		t.Fatalf("merged = %#v, want changed record kept", merged)
+	}
+}
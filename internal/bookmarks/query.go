@@ -0,0 +1,98 @@
+package bookmarks
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Query selects bookmarks. It is a space-separated list of terms that must
+// all match:
+//
+//	tag:<tag>     bookmark has the tag
+//	name:<glob>   name matches the glob (path.Match syntax)
+//	path:<text>   path contains the text
+//	<text>        name, path, tags or description contain the text
+//
+// Matching is case-insensitive. A leading "-" negates a term.
+type Query struct {
+	terms []queryTerm
+}
+
+type queryTerm struct {
+	field  string
+	value  string
+	negate bool
+}
+
+// ParseQuery parses a query string. An empty query matches everything.
+func ParseQuery(input string) (Query, error) {
+	q := Query{}
+	for _, word := range strings.Fields(input) {
+		term := queryTerm{}
+		if strings.HasPrefix(word, "-") && len(word) > 1 {
+			term.negate = true
+			word = word[1:]
+		}
+		field, value, ok := strings.Cut(word, ":")
+		if !ok {
+			field, value = "", word
+		}
+		term.field = strings.ToLower(field)
+		term.value = strings.ToLower(value)
+		switch term.field {
+		case "", "tag", "path":
+		case "name":
+			if _, err := path.Match(term.value, ""); err != nil {
+				return q, fmt.Errorf("invalid name pattern %q: %w", value, err)
+			}
+		default:
+			return q, fmt.Errorf("unknown query field: %s", field)
+		}
+		if term.value == "" {
+			return q, fmt.Errorf("empty query term: %s", word)
+		}
+		q.terms = append(q.terms, term)
+	}
+	return q, nil
+}
+
+// Match reports whether the bookmark satisfies every term.
+func (q Query) Match(entry Bookmark) bool {
+	for _, term := range q.terms {
+		if term.match(entry) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the entries that match the query, preserving order.
+func (q Query) Filter(entries []Bookmark) []Bookmark {
+	filtered := make([]Bookmark, 0, len(entries))
+	for _, entry := range entries {
+		if q.Match(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+func (t queryTerm) match(entry Bookmark) bool {
+	switch t.field {
+	case "tag":
+		return ContainsTag(entry.Tags, t.value)
+	case "name":
+		ok, _ := path.Match(t.value, strings.ToLower(entry.Name))
+		return ok
+	case "path":
+		return strings.Contains(strings.ToLower(entry.Path), t.value)
+	}
+	text := strings.ToLower(strings.Join([]string{
+		entry.Name,
+		entry.Path,
+		tagsToString(entry.Tags),
+		entry.Description,
+	}, " "))
+	return strings.Contains(text, t.value)
+}
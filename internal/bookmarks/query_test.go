@@ -0,0 +1,51 @@
+package bookmarks
+
+import (
+	"testing"
+)
+
+func TestQuery_Match(t *testing.T) {
+	entry := Bookmark{
+		Name:        "billing-api",
+		Path:        "/home/me/work/acme/billing",
+		Tags:        []string{"service", "lang/go"},
+		Description: "Payments backend",
+	}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"", true},
+		{"tag:service", true},
+		{"tag:SERVICE", true},
+		{"tag:web", false},
+		{"name:billing-*", true},
+		{"name:*-web", false},
+		{"path:/work/acme", true},
+		{"payments", true},
+		{"tag:service path:acme", true},
+		{"tag:service path:other", false},
+		{"-tag:web", true},
+		{"-tag:service", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) error = %v", tt.query, err)
+			}
+			if got := q.Match(entry); got != tt.want {
+				t.Fatalf("Match(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	for _, input := range []string{"color:red", "tag:", "name:["} {
+		if _, err := ParseQuery(input); err == nil {
+			t.Fatalf("ParseQuery(%q) expected error", input)
+		}
+	}
+}
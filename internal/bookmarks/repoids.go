@@ -0,0 +1,92 @@
+package bookmarks
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RepoIDs maps the git common directory of a repository on this machine to
+// its ID, the root commit that repo-relative bookmarks are keyed on. Finding
+// the root commit walks the whole history, so it is looked up once per
+// repository.
+type RepoIDs map[string]string
+
+// DefaultRepoIDsPath returns the location of the repository ID cache.
+func DefaultRepoIDsPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "repos"), nil
+}
+
+// LoadRepoIDs reads the cache written by SaveRepoIDs. Missing files return an
+// empty set.
+func LoadRepoIDs(path string) (RepoIDs, error) {
+	ids := RepoIDs{}
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ids, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		dir, id, ok := strings.Cut(line, "\t")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("%s:%d: expected git dir and repository id", path, lineNum)
+		}
+		ids[unescapeField(dir)] = id
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// SaveRepoIDs writes the repository ID cache atomically.
+func SaveRepoIDs(path string, ids RepoIDs) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	dirs := make([]string, 0, len(ids))
+	for d := range ids {
+		dirs = append(dirs, d)
+	}
+	sort.Strings(dirs)
+	var b strings.Builder
+	for _, d := range dirs {
+		fmt.Fprintf(&b, "%s\t%s\n", escapeField(d), ids[d])
+	}
+
+	tmp, err := os.CreateTemp(dir, "repos-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,30 @@
+package bookmarks
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSaveLoadRepoIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "repos")
+	ids := RepoIDs{
+		"/src/app/.git":       "3f1c2a9e8b7d6c5f4e3a2b1c0d9e8f7a6b5c4d3e",
+		"/src/odd\tname/.git": "9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b",
+	}
+	if err := SaveRepoIDs(path, ids); err != nil {
+		t.Fatalf("SaveRepoIDs() error = %v", err)
+	}
+	got, err := LoadRepoIDs(path)
+	if err != nil {
+		t.Fatalf("LoadRepoIDs() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, ids) {
+		t.Fatalf("LoadRepoIDs() = %#v, want %#v", got, ids)
+	}
+
+	missing, err := LoadRepoIDs(filepath.Join(t.TempDir(), "repos"))
+	if err != nil || len(missing) != 0 {
+		t.Fatalf("LoadRepoIDs(missing) = %#v, %v", missing, err)
+	}
+}
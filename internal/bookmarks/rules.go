@@ -0,0 +1,110 @@
+package bookmarks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Rule adds tags to bookmarks whose directory matches a condition. Rules are
+// written in config as `rule = <kind> <arg> -> <tag>[,<tag>...]`:
+//
+//	rule = under ~/work/acme -> client/acme
+//	rule = has go.mod -> lang/go
+//	rule = name *-infra -> infra
+//
+// "under" matches the directory and everything below it, "has" matches
+// directories containing the named file or directory, and "name" matches the
+// directory's base name against a glob.
+type Rule struct {
+	Kind string
+	Arg  string
+	Tags []string
+}
+
+// ParseRule parses the value of a `rule` config line. A leading "~/" in an
+// "under" path is expanded to the home directory.
+func ParseRule(input string) (Rule, error) {
+	cond, tags, ok := strings.Cut(input, "->")
+	if !ok {
+		return Rule{}, fmt.Errorf("rule %q: expected <condition> -> <tags>", input)
+	}
+	kind, arg, _ := strings.Cut(strings.TrimSpace(cond), " ")
+	rule := Rule{
+		Kind: strings.ToLower(kind),
+		Arg:  strings.TrimSpace(arg),
+		Tags: normalizeTags(tags),
+	}
+	if rule.Arg == "" {
+		return Rule{}, fmt.Errorf("rule %q: missing argument for %q", input, kind)
+	}
+	if len(rule.Tags) == 0 {
+		return Rule{}, fmt.Errorf("rule %q: no tags", input)
+	}
+
+	switch rule.Kind {
+	case "under":
+		if rest, ok := strings.CutPrefix(rule.Arg, "~/"); ok || rule.Arg == "~" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return Rule{}, fmt.Errorf("resolve home dir: %w", err)
+			}
+			rule.Arg = filepath.Join(home, rest)
+		}
+		if !filepath.IsAbs(rule.Arg) {
+			return Rule{}, fmt.Errorf("rule %q: under needs an absolute path", input)
+		}
+		rule.Arg = filepath.Clean(rule.Arg)
+	case "has":
+	case "name":
+		if _, err := filepath.Match(rule.Arg, ""); err != nil {
+			return Rule{}, fmt.Errorf("rule %q: %w", input, err)
+		}
+	default:
+		return Rule{}, fmt.Errorf("rule %q: unknown condition %q (expected under, has or name)", input, kind)
+	}
+	return rule, nil
+}
+
+// Matches reports whether the rule applies to dir.
+func (r Rule) Matches(dir string) bool {
+	dir = filepath.Clean(dir)
+	switch r.Kind {
+	case "under":
+		return dir == r.Arg || strings.HasPrefix(dir, r.Arg+string(filepath.Separator))
+	case "has":
+		_, err := os.Stat(filepath.Join(dir, r.Arg))
+		return err == nil
+	case "name":
+		ok, _ := filepath.Match(r.Arg, filepath.Base(dir))
+		return ok
+	}
+	return false
+}
+
+// ApplyRules returns the tags of every rule matching dir, in rule order and
+// without duplicates.
+func ApplyRules(rules []Rule, dir string) []string {
+	var tags []string
+	for _, r := range rules {
+		if r.Matches(dir) {
+			tags = AddTags(tags, r.Tags)
+		}
+	}
+	return tags
+}
+
+// AddTags returns tags followed by any extra tags it does not already contain.
+func AddTags(tags, extra []string) []string {
+	result := append([]string{}, tags...)
+	for _, t := range extra {
+		if !ContainsTag(result, t) {
+			result = append(result, t)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
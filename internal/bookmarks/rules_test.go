@@ -0,0 +1,92 @@
+package bookmarks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseRule(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	tests := []struct {
+		input   string
+		want    Rule
+		wantErr bool
+	}{
+		{input: "under ~/work/acme -> client/acme", want: Rule{Kind: "under", Arg: "/home/me/work/acme", Tags: []string{"client/acme"}}},
+		{input: "has go.mod -> lang/go", want: Rule{Kind: "has", Arg: "go.mod", Tags: []string{"lang/go"}}},
+		{input: "NAME *-infra -> Infra, ops", want: Rule{Kind: "name", Arg: "*-infra", Tags: []string{"infra", "ops"}}},
+		{input: "under /srv/ -> srv", want: Rule{Kind: "under", Arg: "/srv", Tags: []string{"srv"}}},
+		{input: "has go.mod", wantErr: true},
+		{input: "has -> lang/go", wantErr: true},
+		{input: "has go.mod -> ", wantErr: true},
+		{input: "under work -> x", wantErr: true},
+		{input: "name [ -> x", wantErr: true},
+		{input: "contains go.mod -> lang/go", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRule(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseRule() = %#v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRule() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseRule() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRules(t *testing.T) {
+	root := t.TempDir()
+	acme := filepath.Join(root, "work", "acme")
+	api := filepath.Join(acme, "api")
+	infra := filepath.Join(root, "ops", "cloud-infra")
+	for _, dir := range []string{api, infra} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(api, "go.mod"), nil, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var rules []Rule
+	for _, r := range []string{
+		"under " + acme + " -> client/acme",
+		"has go.mod -> lang/go",
+		"name *-infra -> infra",
+		"under " + root + " -> mine, lang/go",
+	} {
+		rule, err := ParseRule(r)
+		if err != nil {
+			t.Fatalf("ParseRule(%q) error = %v", r, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	tests := []struct {
+		dir  string
+		want []string
+	}{
+		{dir: api, want: []string{"client/acme", "lang/go", "mine"}},
+		{dir: acme, want: []string{"client/acme", "mine", "lang/go"}},
+		{dir: acme + "-old", want: []string{"mine", "lang/go"}},
+		{dir: infra, want: []string{"infra", "mine", "lang/go"}},
+		{dir: "/elsewhere", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			if got := ApplyRules(rules, tt.dir); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ApplyRules(%q) = %#v, want %#v", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
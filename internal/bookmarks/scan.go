@@ -0,0 +1,140 @@
+package bookmarks
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Candidate is a directory proposed as a new bookmark.
+type Candidate struct {
+	Name    string
+	Path    string
+	Tags    []string
+	Markers []string
+}
+
+// projectMarkers lists files or directories that identify a project root and
+// the tag each implies (empty for none).
+var projectMarkers = []struct {
+	name string
+	tag  string
+}{
+	{".git", ""},
+	{"go.mod", "lang/go"},
+	{"package.json", "lang/node"},
+	{"Cargo.toml", "lang/rust"},
+	{"pyproject.toml", "lang/python"},
+}
+
+// skipDirs are never descended into while scanning.
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"dist":         true,
+	"build":        true,
+}
+
+// Scan walks root up to depth levels below it and returns one candidate per
+// project root. Project roots are not descended into. Paths already in
+// existing are skipped, and proposed names are unique among existing names
+// and each other.
+func Scan(root string, depth int, existing []Bookmark) ([]Candidate, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	bookmarked := map[string]bool{}
+	names := map[string]bool{}
+	for _, e := range existing {
+		bookmarked[filepath.Clean(e.Path)] = true
+		names[e.Name] = true
+	}
+
+	var candidates []Candidate
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return fs.SkipDir
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()]) {
+			return fs.SkipDir
+		}
+
+		markers, tags := detectProject(path)
+		if len(markers) > 0 {
+			if !bookmarked[path] {
+				candidates = append(candidates, Candidate{
+					Name:    uniqueName(path, names),
+					Path:    path,
+					Tags:    tags,
+					Markers: markers,
+				})
+			}
+			if path != root {
+				return fs.SkipDir
+			}
+		}
+		if levels(root, path) >= depth {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// DetectTags returns the tags implied by project markers in dir.
+func DetectTags(dir string) []string {
+	_, tags := detectProject(dir)
+	return tags
+}
+
+func detectProject(dir string) ([]string, []string) {
+	var markers, tags []string
+	for _, m := range projectMarkers {
+		if _, err := os.Stat(filepath.Join(dir, m.name)); err != nil {
+			continue
+		}
+		markers = append(markers, m.name)
+		if m.tag != "" {
+			tags = append(tags, m.tag)
+		}
+	}
+	return markers, tags
+}
+
+// uniqueName proposes the directory name, falling back to parent-name and
+// then a numeric suffix when taken. The chosen name is recorded in taken.
+func uniqueName(path string, taken map[string]bool) string {
+	base := filepath.Base(path)
+	name := base
+	if taken[name] {
+		if parent := filepath.Base(filepath.Dir(path)); parent != "." && parent != string(filepath.Separator) {
+			name = parent + "-" + base
+		}
+	}
+	for i := 2; taken[name]; i++ {
+		name = base + "-" + strconv.Itoa(i)
+	}
+	taken[name] = true
+	return name
+}
+
+func levels(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
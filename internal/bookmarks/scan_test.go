@@ -0,0 +1,50 @@
+package bookmarks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScan_DetectsProjectsAndSkipsBookmarked(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"work/api/go.mod",
+		"work/api/internal/tool/go.mod", // nested in a project: not reported
+		"work/web/package.json",
+		"work/web/node_modules/dep/package.json",
+		"oss/api/Cargo.toml",
+		"oss/api/.git/HEAD",
+		"py/svc/pyproject.toml",
+		"deep/a/b/c/go.mod", // beyond depth
+		"known/go.mod",
+	}
+	for _, f := range files {
+		p := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	existing := []Bookmark{
+		{Name: "known", Path: filepath.Join(root, "known")},
+		{Name: "svc", Path: "/elsewhere/svc"},
+	}
+
+	got, err := Scan(root, 3, existing)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	want := []Candidate{
+		{Name: "api", Path: filepath.Join(root, "oss/api"), Tags: []string{"lang/rust"}, Markers: []string{".git", "Cargo.toml"}},
+		{Name: "py-svc", Path: filepath.Join(root, "py/svc"), Tags: []string{"lang/python"}, Markers: []string{"pyproject.toml"}},
+		{Name: "work-api", Path: filepath.Join(root, "work/api"), Tags: []string{"lang/go"}, Markers: []string{"go.mod"}},
+		{Name: "web", Path: filepath.Join(root, "work/web"), Tags: []string{"lang/node"}, Markers: []string{"package.json"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Scan() =\n%#v\nwant\n%#v", got, want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,8 +17,35 @@ type Bookmark struct {
 	Path      string
 	Tags      []string
 	CreatedAt time.Time
+	// UpdatedAt is set whenever the bookmark is modified; zero if never updated.
+	UpdatedAt time.Time
+	// Description is a one-line summary of why the bookmark exists.
+	Description string
+	// Notes is free-form, possibly multi-line text.
+	Notes string
+	// Hook is a shell snippet the shell integration runs after entering the
+	// bookmark, once trusted.
+	Hook string
+	// Repo identifies the repository of a repo-relative bookmark by its root
+	// commit, and RepoPath is its path relative to the work tree root. Both
+	// are empty for absolute bookmarks.
+	Repo     string
+	RepoPath string
 }
 
+// IsRepoRelative reports whether the bookmark resolves relative to the
+// current git worktree.
+func (b Bookmark) IsRepoRelative() bool {
+	return b.Repo != "" && b.RepoPath != ""
+}
+
+// maxFields is the number of TSV columns in the current store format. Older
+// stores with only the first four columns remain readable.
+const maxFields = 10
+
+// maxLineSize bounds a single store line; notes can make lines long.
+const maxLineSize = 1024 * 1024
+
 // DefaultPath returns the default TSV storage path.
 func DefaultPath() (string, error) {
 	if xdg := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME")); xdg != "" {
@@ -41,8 +69,13 @@ func Load(path string) ([]Bookmark, error) {
 		return nil, err
 	}
 	defer file.Close()
+	return Read(file)
+}
 
-	scanner := bufio.NewScanner(file)
+// Read parses bookmarks in the TSV store format.
+func Read(r io.Reader) ([]Bookmark, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
 	lineNum := 0
 	entries := []Bookmark{}
 	for scanner.Scan() {
@@ -52,8 +85,8 @@ func Load(path string) ([]Bookmark, error) {
 			continue
 		}
 		parts := strings.Split(line, "\t")
-		if len(parts) != 4 {
-			return nil, fmt.Errorf("line %d: expected 4 fields", lineNum)
+		if len(parts) < 4 || len(parts) > maxFields {
+			return nil, fmt.Errorf("line %d: expected 4 to %d fields", lineNum, maxFields)
 		}
 		createdAt, err := time.Parse(time.RFC3339, parts[3])
 		if err != nil {
@@ -65,6 +98,25 @@ func Load(path string) ([]Bookmark, error) {
 			Tags:      normalizeTags(parts[2]),
 			CreatedAt: createdAt,
 		}
+		if len(parts) > 4 && parts[4] != "" {
+			entry.UpdatedAt, err = time.Parse(time.RFC3339, parts[4])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: parse updated_at: %w", lineNum, err)
+			}
+		}
+		if len(parts) > 5 {
+			entry.Description = unescapeField(parts[5])
+		}
+		if len(parts) > 6 {
+			entry.Notes = unescapeField(parts[6])
+		}
+		if len(parts) > 7 {
+			entry.Hook = unescapeField(parts[7])
+		}
+		if len(parts) > 9 {
+			entry.Repo = unescapeField(parts[8])
+			entry.RepoPath = unescapeField(parts[9])
+		}
 		entries = append(entries, entry)
 	}
 	if err := scanner.Err(); err != nil {
@@ -88,28 +140,85 @@ func Save(path string, entries []Bookmark) error {
 		_ = os.Remove(tmp.Name())
 	}()
 
-	writer := bufio.NewWriter(tmp)
+	if err := Write(tmp, entries); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Write encodes bookmarks in the TSV store format.
+func Write(w io.Writer, entries []Bookmark) error {
+	writer := bufio.NewWriter(w)
 	for _, entry := range entries {
-		line := fmt.Sprintf("%s\t%s\t%s\t%s\n",
+		fields := []string{
 			entry.Name,
 			entry.Path,
 			tagsToString(entry.Tags),
 			entry.CreatedAt.Format(time.RFC3339),
-		)
+			formatOptionalTime(entry.UpdatedAt),
+			escapeField(entry.Description),
+			escapeField(entry.Notes),
+			escapeField(entry.Hook),
+			escapeField(entry.Repo),
+			escapeField(entry.RepoPath),
+		}
+		// Optional trailing columns are omitted when empty so stores without
+		// them keep the original four-column layout.
+		for len(fields) > 4 && fields[len(fields)-1] == "" {
+			fields = fields[:len(fields)-1]
+		}
+		line := strings.Join(fields, "\t") + "\n"
 		if _, err := writer.WriteString(line); err != nil {
 			return err
 		}
 	}
-	if err := writer.Flush(); err != nil {
-		return err
+	return writer.Flush()
+}
+
+// escapeField encodes backslashes, tabs and newlines so free text fits in a
+// single TSV column.
+func escapeField(s string) string {
+	return fieldEscaper.Replace(s)
+}
+
+func unescapeField(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
 	}
-	if err := tmp.Sync(); err != nil {
-		return err
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(s[i])
+		}
 	}
-	if err := tmp.Close(); err != nil {
-		return err
+	return b.String()
+}
+
+var fieldEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
 	}
-	return os.Rename(tmp.Name(), path)
+	return t.UTC().Format(time.RFC3339)
 }
 
 // NormalizeTags converts a comma-separated tag string into normalized tags.
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -76,3 +77,69 @@ func TestSaveLoad_RoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestSaveLoad_DescriptionAndNotes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bookmarks.tsv")
+	entries := []Bookmark{{
+		Name:        "hotfix",
+		Path:        "/tmp/hotfix",
+		CreatedAt:   time.Date(2026, 2, 11, 12, 0, 0, 0, time.UTC),
+		Description: "prod hotfix branch checkout",
+		Notes:       "delete after release\n\tsee C:\\tickets\\42",
+	}}
+	if err := Save(path, entries); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Description != entries[0].Description || got[0].Notes != entries[0].Notes {
+		t.Fatalf("Load() = %#v, want %#v", got, entries)
+	}
+}
+
+func TestLoad_AcceptsFourColumnLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bookmarks.tsv")
+	if err := os.WriteFile(path, []byte("a\t/tmp/a\twork\t2026-02-11T12:00:00Z\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Description != "" || !got[0].UpdatedAt.IsZero() {
+		t.Fatalf("Load() = %#v", got)
+	}
+}
+
+func TestSaveLoad_RepoRelative(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bookmarks.tsv")
+	entries := []Bookmark{{
+		Name:      "api-handlers",
+		Path:      "/src/app/api/handlers",
+		CreatedAt: time.Date(2026, 2, 11, 12, 0, 0, 0, time.UTC),
+		Repo:      "3f1c2a9e8b7d6c5f4e3a2b1c0d9e8f7a6b5c4d3e",
+		RepoPath:  "api/odd\tname\\x",
+	}}
+	if err := Save(path, entries); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields := strings.Split(strings.TrimSuffix(string(data), "\n"), "\t"); len(fields) != 10 {
+		t.Fatalf("stored line has %d fields, want 10: %q", len(fields), data)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Repo != entries[0].Repo || got[0].RepoPath != entries[0].RepoPath || !got[0].IsRepoRelative() {
+		t.Fatalf("Load() = %#v, want %#v", got, entries)
+	}
+}
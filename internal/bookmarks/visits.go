@@ -0,0 +1,199 @@
+package bookmarks
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Visit counts how often a directory was entered and when it was last.
+type Visit struct {
+	Path  string
+	Count int
+	Last  time.Time
+}
+
+// Visits maps a directory to its visit statistics.
+type Visits map[string]Visit
+
+// Record counts a visit to path at now.
+func (v Visits) Record(path string, now time.Time) {
+	visit := v[path]
+	visit.Path = path
+	visit.Count++
+	visit.Last = now.UTC()
+	v[path] = visit
+}
+
+// DefaultVisitsPath returns the location of the per-bookmark visit
+// statistics, keyed by bookmark path.
+func DefaultVisitsPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "visits"), nil
+}
+
+// DefaultHotDirsPath returns the location of the log of frequently visited
+// directories that are not bookmarked.
+func DefaultHotDirsPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "hotdirs"), nil
+}
+
+// LoadVisits reads visit statistics written by SaveVisits. Missing files
+// return an empty set.
+func LoadVisits(path string) (Visits, error) {
+	visits := Visits{}
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return visits, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		parts := strings.Split(line, "\t")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected path, count and last visit", path, lineNum)
+		}
+		count, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid count: %w", path, lineNum, err)
+		}
+		last, err := time.Parse(time.RFC3339, parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid time: %w", path, lineNum, err)
+		}
+		dir := unescapeField(parts[0])
+		visits[dir] = Visit{Path: dir, Count: count, Last: last}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return visits, nil
+}
+
+// SaveVisits writes visit statistics, most recent first. When limit is
+// positive only the limit most recently visited entries are kept.
+func SaveVisits(path string, visits Visits, limit int) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	list := make([]Visit, 0, len(visits))
+	for _, v := range visits {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Last.Equal(list[j].Last) {
+			return list[i].Last.After(list[j].Last)
+		}
+		return list[i].Path < list[j].Path
+	})
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+
+	var b strings.Builder
+	for _, v := range list {
+		fmt.Fprintf(&b, "%s\t%d\t%s\n", escapeField(v.Path), v.Count, v.Last.UTC().Format(time.RFC3339))
+	}
+
+	// Several shells may record at once; write atomically so readers never
+	// see a partial file.
+	tmp, err := os.CreateTemp(dir, "visits-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+const (
+	// visitsLockWait bounds how long UpdateVisits waits for another process.
+	visitsLockWait = 2 * time.Second
+	// visitsLockStale is the age after which a lock left by a crashed
+	// process is removed.
+	visitsLockStale = 10 * time.Second
+)
+
+// UpdateVisits loads the visit statistics at path, passes them to fn and
+// saves the result with SaveVisits. A lock file next to path serializes
+// concurrent updates so visits recorded by several shells are not lost.
+func UpdateVisits(path string, limit int, fn func(Visits)) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	visits, err := LoadVisits(path)
+	if err != nil {
+		return err
+	}
+	fn(visits)
+	return SaveVisits(path, visits, limit)
+}
+
+// lockFile creates lock exclusively, retrying until visitsLockWait passes,
+// and returns a function that removes it.
+func lockFile(lock string) (func(), error) {
+	deadline := time.Now().Add(visitsLockWait)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > visitsLockStale {
+			_ = os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s", lock)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Within reports whether dir is root or below it.
+func Within(dir, root string) bool {
+	dir, root = filepath.Clean(dir), filepath.Clean(root)
+	return dir == root || strings.HasPrefix(dir, strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator))
+}
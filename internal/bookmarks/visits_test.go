@@ -0,0 +1,109 @@
+package bookmarks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadVisits_KeepsMostRecent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "visits")
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	visits := Visits{}
+	visits.Record("/src/api", start)
+	visits.Record("/src/web", start.Add(time.Minute))
+	visits.Record("/src/api", start.Add(2*time.Minute))
+	visits.Record("/tmp/with\ttab", start.Add(3*time.Minute))
+	if err := SaveVisits(path, visits, 2); err != nil {
+		t.Fatalf("SaveVisits() error = %v", err)
+	}
+
+	got, err := LoadVisits(path)
+	if err != nil {
+		t.Fatalf("LoadVisits() error = %v", err)
+	}
+	want := Visits{
+		"/src/api":       {Path: "/src/api", Count: 2, Last: start.Add(2 * time.Minute)},
+		"/tmp/with\ttab": {Path: "/tmp/with\ttab", Count: 1, Last: start.Add(3 * time.Minute)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("LoadVisits() = %#v, want %#v", got, want)
+	}
+}
+
+func TestLoadVisits_MissingFile(t *testing.T) {
+	got, err := LoadVisits(filepath.Join(t.TempDir(), "visits"))
+	if err != nil || len(got) != 0 {
+		t.Fatalf("LoadVisits() = %#v, %v; want empty", got, err)
+	}
+}
+
+func TestWithin(t *testing.T) {
+	tests := []struct {
+		dir, root string
+		want      bool
+	}{
+		{"/src/api", "/src/api", true},
+		{"/src/api/handlers", "/src/api", true},
+		{"/src/api-old", "/src/api", false},
+		{"/src", "/src/api", false},
+		{"/anything", "/", true},
+	}
+	for _, tt := range tests {
+		if got := Within(tt.dir, tt.root); got != tt.want {
+			t.Errorf("Within(%q, %q) = %v, want %v", tt.dir, tt.root, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateVisits_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "visits")
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- UpdateVisits(path, 0, func(v Visits) { v.Record("/src/api", now) })
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("UpdateVisits() error = %v", err)
+		}
+	}
+
+	got, err := LoadVisits(path)
+	if err != nil {
+		t.Fatalf("LoadVisits() error = %v", err)
+	}
+	if got["/src/api"].Count != 20 {
+		t.Fatalf("count = %d, want 20", got["/src/api"].Count)
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Fatalf("lock file left behind: %v", err)
+	}
+}
+
+func TestUpdateVisits_RemovesStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "visits")
+	lock := path + ".lock"
+	if err := os.WriteFile(lock, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateVisits(path, 0, func(v Visits) { v.Record("/src/api", time.Now()) }); err != nil {
+		t.Fatalf("UpdateVisits() error = %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+// Package gitinfo inspects git work trees for bookmarked directories.
+package gitinfo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotRepo is returned for directories that are not inside a git work tree.
+var ErrNotRepo = errors.New("not a git work tree")
+
+// ErrNoCommits is returned by RootCommit for repositories without commits.
+var ErrNoCommits = errors.New("repository has no commits yet")
+
+// Status summarizes the state of a work tree.
+type Status struct {
+	Branch      string
+	Detached    bool
+	HasUpstream bool
+	Ahead       int
+	Behind      int
+	Dirty       int
+	LastCommit  time.Time
+}
+
+// Result pairs a directory with its status or the error that prevented
+// inspecting it.
+type Result struct {
+	Dir    string
+	Status Status
+	Err    error
+}
+
+// Inspect returns the status of the work tree containing dir.
+func Inspect(ctx context.Context, dir string) (Status, error) {
+	st := Status{}
+	out, err := git(ctx, dir, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return st, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "# branch.head "):
+			st.Branch = strings.TrimPrefix(line, "# branch.head ")
+			if st.Branch == "(detached)" {
+				st.Detached = true
+			}
+		case strings.HasPrefix(line, "# branch.upstream "):
+			st.HasUpstream = true
+		case strings.HasPrefix(line, "# branch.ab "):
+			fields := strings.Fields(strings.TrimPrefix(line, "# branch.ab "))
+			if len(fields) == 2 {
+				st.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				st.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+			}
+		case strings.HasPrefix(line, "#"), line == "":
+		default:
+			st.Dirty++
+		}
+	}
+
+	// Repositories without commits have no log; that is not an error.
+	if out, err := git(ctx, dir, "log", "-1", "--format=%ct"); err == nil {
+		if sec, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64); err == nil {
+			st.LastCommit = time.Unix(sec, 0)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return st, err
+	}
+	return st, nil
+}
+
+// Repo identifies the work tree containing a directory. Worktrees of the same
+// repository share CommonDir.
+type Repo struct {
+	Root      string
+	CommonDir string
+}
+
+// RepoOf returns the work tree root and git common directory for dir.
+func RepoOf(ctx context.Context, dir string) (Repo, error) {
+	out, err := git(ctx, dir, "rev-parse", "--path-format=absolute", "--show-toplevel", "--git-common-dir")
+	if err != nil {
+		return Repo{}, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 || lines[0] == "" {
+		return Repo{}, ErrNotRepo
+	}
+	return Repo{Root: lines[0], CommonDir: lines[1]}, nil
+}
+
+// RootCommit returns the root commit of the repository containing dir, the
+// smallest hash when there are several. Clones of a repository, on any
+// machine, share it. It walks the whole history, so callers should cache it.
+func RootCommit(ctx context.Context, dir string) (string, error) {
+	if _, err := git(ctx, dir, "rev-parse", "-q", "--verify", "HEAD"); err != nil {
+		if errors.Is(err, ErrNotRepo) || ctx.Err() != nil {
+			return "", err
+		}
+		return "", ErrNoCommits
+	}
+	out, err := git(ctx, dir, "rev-list", "--max-parents=0", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	roots := strings.Fields(string(out))
+	if len(roots) == 0 {
+		return "", ErrNoCommits
+	}
+	sort.Strings(roots)
+	return roots[0], nil
+}
+
+// InspectAll inspects dirs concurrently with at most workers git processes at
+// once. Each directory gets its own timeout. Results follow the order of dirs.
+func InspectAll(ctx context.Context, dirs []string, timeout time.Duration, workers int) []Result {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]Result, len(dirs))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, dir := range dirs {
+		wg.Add(1)
+		go func(i int, dir string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			repoCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			st, err := Inspect(repoCtx, dir)
+			if errors.Is(repoCtx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out after %s", timeout)
+			}
+			results[i] = Result{Dir: dir, Status: st, Err: err}
+		}(i, dir)
+	}
+	wg.Wait()
+	return results
+}
+
+// Cache keeps recent results so repeated views do not re-run git.
+type Cache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	result Result
+	at     time.Time
+}
+
+// NewCache returns a cache whose entries expire after ttl.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, entries: map[string]cacheEntry{}}
+}
+
+// Get returns a cached result that has not expired.
+func (c *Cache) Get(dir string) (Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[dir]
+	if !ok || time.Since(entry.at) > c.ttl {
+		return Result{}, false
+	}
+	return entry.result, true
+}
+
+// Put stores a result.
+func (c *Cache) Put(result Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[result.Dir] = cacheEntry{result: result, at: time.Now()}
+}
+
+// InspectAll is like the package-level InspectAll but serves fresh entries
+// from the cache and stores new results.
+func (c *Cache) InspectAll(ctx context.Context, dirs []string, timeout time.Duration, workers int) []Result {
+	results := make([]Result, len(dirs))
+	var missing []string
+	var missingIdx []int
+	for i, dir := range dirs {
+		if r, ok := c.Get(dir); ok {
+			results[i] = r
+			continue
+		}
+		missing = append(missing, dir)
+		missingIdx = append(missingIdx, i)
+	}
+	for j, r := range InspectAll(ctx, missing, timeout, workers) {
+		c.Put(r)
+		results[missingIdx[j]] = r
+	}
+	return results
+}
+
+func git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	// ErrNotRepo is recognized from git's English messages.
+	cmd.Env = append(cmd.Environ(), "GIT_OPTIONAL_LOCKS=0", "LC_ALL=C")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if strings.Contains(msg, "not a git repository") || strings.Contains(msg, "cannot change to") {
+			return nil, ErrNotRepo
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,141 @@
+package gitinfo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func setupGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "bm test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "bm@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "bm test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "bm@example.com")
+}
+
+func run(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+func TestInspect_BranchAheadBehindDirty(t *testing.T) {
+	setupGit(t)
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	clone := filepath.Join(root, "clone")
+	run(t, root, "init", "-q", "--bare", "-b", "main", remote)
+	run(t, root, "clone", "-q", remote, clone)
+	run(t, clone, "commit", "-q", "--allow-empty", "-m", "one")
+	run(t, clone, "push", "-q", "origin", "main")
+	run(t, clone, "commit", "-q", "--allow-empty", "-m", "two")
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(clone, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	st, err := Inspect(context.Background(), clone)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if st.Branch != "main" || !st.HasUpstream || st.Ahead != 1 || st.Behind != 0 || st.Dirty != 2 {
+		t.Fatalf("Inspect() = %#v", st)
+	}
+	if time.Since(st.LastCommit) > time.Hour {
+		t.Fatalf("LastCommit = %v, want recent", st.LastCommit)
+	}
+}
+
+func TestInspectAll_NotRepoAndCache(t *testing.T) {
+	setupGit(t)
+	plain := t.TempDir()
+	repo := t.TempDir()
+	run(t, repo, "init", "-q", "-b", "trunk")
+
+	cache := NewCache(time.Minute)
+	results := cache.InspectAll(context.Background(), []string{plain, repo}, 5*time.Second, 2)
+	if !errors.Is(results[0].Err, ErrNotRepo) {
+		t.Fatalf("results[0].Err = %v, want ErrNotRepo", results[0].Err)
+	}
+	if results[1].Err != nil || results[1].Status.Branch != "trunk" {
+		t.Fatalf("results[1] = %#v", results[1])
+	}
+	if cached, ok := cache.Get(repo); !ok || cached.Status.Branch != "trunk" {
+		t.Fatalf("cache.Get() = %#v, %v", cached, ok)
+	}
+}
+
+func TestRepoOf_WorktreesShareCommonDir(t *testing.T) {
+	setupGit(t)
+	root := t.TempDir()
+	main := filepath.Join(root, "main")
+	wt := filepath.Join(root, "feature")
+	if err := os.MkdirAll(filepath.Join(main, "sub"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	run(t, main, "init", "-q", "-b", "main")
+	run(t, main, "commit", "-q", "--allow-empty", "-m", "one")
+	run(t, main, "worktree", "add", "-q", "-b", "feature", wt)
+
+	a, err := RepoOf(context.Background(), filepath.Join(main, "sub"))
+	if err != nil {
+		t.Fatalf("RepoOf(main) error = %v", err)
+	}
+	b, err := RepoOf(context.Background(), wt)
+	if err != nil {
+		t.Fatalf("RepoOf(worktree) error = %v", err)
+	}
+	if a.Root != main || b.Root != wt {
+		t.Fatalf("roots = %q, %q; want %q, %q", a.Root, b.Root, main, wt)
+	}
+	if a.CommonDir != b.CommonDir || a.CommonDir != filepath.Join(main, ".git") {
+		t.Fatalf("common dirs = %q, %q", a.CommonDir, b.CommonDir)
+	}
+	if _, err := RepoOf(context.Background(), t.TempDir()); !errors.Is(err, ErrNotRepo) {
+		t.Fatalf("RepoOf(plain) error = %v, want ErrNotRepo", err)
+	}
+}
+
+func TestRootCommit_SharedByWorktreesAndClones(t *testing.T) {
+	setupGit(t)
+	root := t.TempDir()
+	main := filepath.Join(root, "main")
+	empty := filepath.Join(root, "empty")
+	run(t, root, "init", "-q", "-b", "main", main)
+	run(t, root, "init", "-q", "-b", "main", empty)
+	run(t, main, "commit", "-q", "--allow-empty", "-m", "one")
+	run(t, main, "commit", "-q", "--allow-empty", "-m", "two")
+	run(t, main, "worktree", "add", "-q", "-b", "feature", filepath.Join(root, "feature"))
+	run(t, root, "clone", "-q", main, filepath.Join(root, "clone"))
+
+	var ids []string
+	for _, dir := range []string{"main", "feature", "clone"} {
+		id, err := RootCommit(context.Background(), filepath.Join(root, dir))
+		if err != nil {
+			t.Fatalf("RootCommit(%s) error = %v", dir, err)
+		}
+		ids = append(ids, id)
+	}
+	if ids[0] == "" || ids[0] != ids[1] || ids[0] != ids[2] {
+		t.Fatalf("ids = %q, want one shared root commit", ids)
+	}
+	if _, err := RootCommit(context.Background(), empty); !errors.Is(err, ErrNoCommits) {
+		t.Fatalf("RootCommit(empty) error = %v, want ErrNoCommits", err)
+	}
+	if _, err := RootCommit(context.Background(), t.TempDir()); !errors.Is(err, ErrNotRepo) {
+		t.Fatalf("RootCommit(plain) error = %v, want ErrNotRepo", err)
+	}
+}